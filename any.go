@@ -0,0 +1,142 @@
+package clilog
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// AnyFormat controls how a [CLIHandler] renders the values of kind
+// [slog.KindAny] that are not errors and do not implement
+// [encoding.TextMarshaler] or [fmt.Stringer].
+//
+// The string representation of these values is resolved in the
+// following order:
+//
+//  1. [slog.LogValuer] values are resolved by the handler before
+//     rendering them.
+//  2. Errors are rendered using their Error method.
+//  3. [encoding.TextMarshaler] values are rendered using their
+//     MarshalText method.
+//  4. [fmt.Stringer] values are rendered using their String method,
+//     unless DisableStringer is set.
+//  5. Other values are rendered using reflection, according to the
+//     configured AnyFormat.
+type AnyFormat int
+
+// Any formats.
+const (
+	// AnyJSON renders maps, slices, arrays and structs as compact
+	// JSON (e.g. {"retries":3,"host":"x"}). Other values and
+	// values that cannot be encoded are rendered like AnyGo.
+	AnyJSON AnyFormat = iota
+
+	// AnyGo renders values using the default format of the fmt
+	// package (%v).
+	AnyGo
+
+	// AnyText renders values like AnyGo.
+	//
+	// Deprecated: Values implementing [encoding.TextMarshaler]
+	// are always rendered using their MarshalText method. Use
+	// AnyGo instead.
+	AnyText
+)
+
+// String returns a name for the format.
+func (f AnyFormat) String() string {
+	switch f {
+	case AnyJSON:
+		return "json"
+	case AnyGo:
+		return "go"
+	case AnyText:
+		return "text"
+	default:
+		return fmt.Sprintf("AnyFormat(%d)", int(f))
+	}
+}
+
+// valueString returns the string representation of v. Values of kind
+// [slog.KindAny] are resolved in the order documented by [AnyFormat].
+func (h *CLIHandler) valueString(v slog.Value) string {
+	if v.Kind() != slog.KindAny {
+		return v.String()
+	}
+	return h.anyString(v.Any())
+}
+
+// anyString returns the string representation of x. Like the fmt
+// package, it recovers from panics in the methods of x, rendering
+// nil receivers as "<nil>".
+func (h *CLIHandler) anyString(x any) (s string) {
+	if x == nil {
+		return "<nil>"
+	}
+
+	method := ""
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(x); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("%%!v(PANIC=%s method: %v)", method, r)
+		}
+	}()
+
+	switch x := x.(type) {
+	case error:
+		method = "Error"
+		return x.Error()
+	case encoding.TextMarshaler:
+		method = "MarshalText"
+		if b, err := x.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	if x, ok := x.(fmt.Stringer); ok && !h.opts.DisableStringer {
+		method = "String"
+		return x.String()
+	}
+
+	if h.opts.AnyFormat == AnyJSON && isComposite(x) {
+		method = "MarshalJSON"
+		if b, err := json.Marshal(x); err == nil {
+			return string(b)
+		}
+	}
+	if h.opts.DisableStringer {
+		return sprintNoMethods(x)
+	}
+	return fmt.Sprint(x)
+}
+
+// isComposite reports whether x is a map, a slice, an array or a
+// struct, or a pointer to one of them. Byte slices are not considered
+// composite.
+func isComposite(x any) bool {
+	if _, ok := x.([]byte); ok {
+		return false
+	}
+	rv := reflect.ValueOf(x)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	default:
+		return false
+	}
+}
+
+// sprintNoMethods formats x like [fmt.Sprint] without calling the
+// methods of x or its elements. The fmt package does not call the
+// methods of values obtained through unexported struct fields.
+func sprintNoMethods(x any) string {
+	v := reflect.ValueOf(struct{ x any }{x}).Field(0).Elem()
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,142 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type testConfig struct {
+	Retries int    `json:"retries"`
+	Host    string `json:"host"`
+}
+
+type testText struct{}
+
+func (testText) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+type testStringer int
+
+func (s testStringer) String() string {
+	if s < 0 {
+		panic("negative")
+	}
+	return "stringer"
+}
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string {
+	return e.msg
+}
+
+func TestCLIHandler_AnyFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *HandlerOptions
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name:  "json struct",
+			opts:  &HandlerOptions{Quote: QuoteNever},
+			attrs: []slog.Attr{slog.Any("cfg", testConfig{Retries: 3, Host: "x"})},
+			want:  `INFO message cfg={"retries":3,"host":"x"}`,
+		},
+		{
+			name:  "json pointer",
+			opts:  &HandlerOptions{Quote: QuoteNever},
+			attrs: []slog.Attr{slog.Any("cfg", &testConfig{Retries: 3, Host: "x"})},
+			want:  `INFO message cfg={"retries":3,"host":"x"}`,
+		},
+		{
+			name:  "json quoted",
+			attrs: []slog.Attr{slog.Any("m", map[string]int{"b": 2, "a": 1})},
+			want:  `INFO message m="{\"a\":1,\"b\":2}"`,
+		},
+		{
+			name:  "json slice",
+			attrs: []slog.Attr{slog.Any("s", []string{"a", "b"}), slog.Any("b", []byte("hi"))},
+			want:  `INFO message s="[\"a\",\"b\"]" b="[104 105]"`,
+		},
+		{
+			name:  "json error",
+			attrs: []slog.Attr{slog.Any("err", errors.New("boom"))},
+			want:  `INFO message err=boom`,
+		},
+		{
+			name:  "json unsupported",
+			attrs: []slog.Attr{slog.Any("f", []func(){nil})},
+			want:  `INFO message f=[<nil>]`,
+		},
+		{
+			name:  "text marshaler",
+			attrs: []slog.Attr{slog.Any("t", testText{}), slog.Any("ts", []testText{{}})},
+			want:  `INFO message t=text ts="[\"text\"]"`,
+		},
+		{
+			name:  "stringer",
+			attrs: []slog.Attr{slog.Any("s", testStringer(1))},
+			want:  `INFO message s=stringer`,
+		},
+		{
+			name:  "panic",
+			attrs: []slog.Attr{slog.Any("s", testStringer(-1)), slog.Any("err", (*testError)(nil))},
+			want:  `INFO message s="%!v(PANIC=String method: negative)" err=<nil>`,
+		},
+		{
+			name:  "DisableStringer",
+			opts:  &HandlerOptions{DisableStringer: true, Quote: QuoteNever},
+			attrs: []slog.Attr{slog.Any("s", testStringer(-1)), slog.Any("ss", map[string]testStringer{"a": -1})},
+			want:  `INFO message s=-1 ss={"a":-1}`,
+		},
+		{
+			name:  "DisableStringer,AnyGo",
+			opts:  &HandlerOptions{DisableStringer: true, AnyFormat: AnyGo, Quote: QuoteNever},
+			attrs: []slog.Attr{slog.Any("s", testStringer(-1)), slog.Any("ss", &[]testStringer{-1, -2})},
+			want:  `INFO message s=-1 ss=&[-1 -2]`,
+		},
+		{
+			name:  "go",
+			opts:  &HandlerOptions{AnyFormat: AnyGo},
+			attrs: []slog.Attr{slog.Any("cfg", testConfig{Retries: 3, Host: "x"}), slog.Any("s", []int{1, 2})},
+			want:  `INFO message cfg="{3 x}" s="[1 2]"`,
+		},
+		{
+			name:  "text",
+			opts:  &HandlerOptions{AnyFormat: AnyText},
+			attrs: []slog.Attr{slog.Any("t", testText{}), slog.Any("s", []int{1, 2})},
+			want:  `INFO message t=text s="[1 2]"`,
+		},
+		{
+			name:  "go text marshaler",
+			opts:  &HandlerOptions{AnyFormat: AnyGo},
+			attrs: []slog.Attr{slog.Any("t", testText{})},
+			want:  `INFO message t=text`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			opts := &HandlerOptions{}
+			if tt.opts != nil {
+				opts = tt.opts
+			}
+			opts.OmitTime = true
+
+			logger := slog.New(NewCLIHandler(&buf, opts))
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "message", tt.attrs...)
+
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.want {
+				t.Errorf("unexpected log line:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package clilog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultQueueSize is the size of the queue of an [AsyncHandler]
+// created with a non-positive size.
+const DefaultQueueSize = 1024
+
+// ErrClosed is returned when a record is passed to a closed handler.
+var ErrClosed = errors.New("clilog: handler closed")
+
+// AsyncHandler is a [slog.Handler] that queues records and passes
+// them to another handler from a background goroutine, so slow
+// writers do not block the caller. If the queue is full, records are
+// dropped.
+//
+// Handlers derived from an AsyncHandler using WithAttrs or WithGroup
+// share its queue.
+type AsyncHandler struct {
+	h slog.Handler
+	q *asyncQueue
+}
+
+// asyncQueue is the queue shared by an AsyncHandler and the handlers
+// derived from it.
+type asyncQueue struct {
+	ch      chan asyncEntry
+	done    chan struct{} // closed when the worker exits
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex // protects closed
+	closed bool
+}
+
+// asyncEntry is an element of the queue. It is either a record or, if
+// flushed is not nil, a flush request.
+type asyncEntry struct {
+	h       slog.Handler
+	ctx     context.Context
+	r       slog.Record
+	flushed chan struct{}
+}
+
+// NewAsyncHandler returns a new [AsyncHandler] that passes records to
+// h. At most size records are queued. If size is not positive, the
+// handler uses DefaultQueueSize.
+func NewAsyncHandler(h slog.Handler, size int) *AsyncHandler {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	q := &asyncQueue{
+		ch:   make(chan asyncEntry, size),
+		done: make(chan struct{}),
+	}
+	go q.run()
+	return &AsyncHandler{h: h, q: q}
+}
+
+// run passes the queued records to their handlers until the queue is
+// closed. Errors returned by the handlers are ignored.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for e := range q.ch {
+		if e.flushed != nil {
+			close(e.flushed)
+			continue
+		}
+		e.h.Handle(e.ctx, e.r) //nolint:errcheck
+	}
+}
+
+// Enabled reports whether the underlying handler handles records at
+// the given level.
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle queues the record. If the queue is full, the record is
+// dropped. It returns ErrClosed if the handler has been closed.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.q.mu.RLock()
+	defer h.q.mu.RUnlock()
+
+	if h.q.closed {
+		return ErrClosed
+	}
+
+	e := asyncEntry{h: h.h, ctx: context.WithoutCancel(ctx), r: r.Clone()}
+	select {
+	case h.q.ch <- e:
+	default:
+		h.q.dropped.Add(1)
+	}
+	return nil
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both
+// the receiver's attributes and the arguments.
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{h: h.h.WithAttrs(attrs), q: h.q}
+}
+
+// WithGroup returns a new Handler with the given group appended to
+// the receiver's existing groups.
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{h: h.h.WithGroup(name), q: h.q}
+}
+
+// Flush waits until all the records queued before the call have been
+// handled.
+func (h *AsyncHandler) Flush() {
+	h.q.mu.RLock()
+	if h.q.closed {
+		h.q.mu.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	h.q.ch <- asyncEntry{flushed: flushed}
+	h.q.mu.RUnlock()
+
+	<-flushed
+}
+
+// Close handles the queued records and stops the background
+// goroutine. Records passed to the handler after Close are rejected
+// with ErrClosed. Close always returns nil.
+func (h *AsyncHandler) Close() error {
+	h.q.mu.Lock()
+	if !h.q.closed {
+		h.q.closed = true
+		close(h.q.ch)
+	}
+	h.q.mu.Unlock()
+
+	<-h.q.done
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was
+// full.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.q.dropped.Load()
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *AsyncHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
@@ -0,0 +1,90 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestAsyncHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewAsyncHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), 0)
+	logger := slog.New(h).With("a", 1)
+
+	logger.Info("first")
+	logger.WithGroup("g").Info("second", "b", 2)
+	h.Flush()
+
+	if got, want := buf.String(), "INFO first a=1\nINFO second a=1 g.b=2\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	logger.Info("third")
+	if err := h.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	if got, want := buf.String(), "INFO first a=1\nINFO second a=1 g.b=2\nINFO third a=1\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	r := slog.NewRecord(testTime, slog.LevelInfo, "closed", 0)
+	if err := h.Handle(context.Background(), r); !errors.Is(err, ErrClosed) {
+		t.Errorf("unexpected error: got: %v, want: %v", err, ErrClosed)
+	}
+
+	// Flush and Close must not block after Close.
+	h.Flush()
+	h.Close()
+}
+
+func TestAsyncHandler_Dropped(t *testing.T) {
+	w := newBlockingWriter()
+
+	h := NewAsyncHandler(NewCLIHandler(w, &HandlerOptions{OmitTime: true}), 1)
+	logger := slog.New(h)
+
+	// The first record blocks the worker, the second one fills the
+	// queue and the rest are dropped.
+	logger.Info("first")
+	<-w.started
+	for i := 0; i < 5; i++ {
+		logger.Info("next")
+	}
+	close(w.unblock)
+	h.Close()
+
+	if got, want := h.Dropped(), uint64(4); got != want {
+		t.Errorf("unexpected number of dropped records: got: %v, want: %v", got, want)
+	}
+	if got, want := w.buf.String(), "INFO first\nINFO next\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+// blockingWriter blocks every write until unblock is closed. It
+// notifies on started every time a write starts.
+type blockingWriter struct {
+	started chan struct{}
+	unblock chan struct{}
+	buf     bytes.Buffer
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		started: make(chan struct{}, 1),
+		unblock: make(chan struct{}),
+	}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.unblock
+	return w.buf.Write(p)
+}
@@ -0,0 +1,56 @@
+package clilog
+
+import "sync"
+
+// maxBufferSize is the maximum capacity of the buffers returned to the
+// pool. Larger buffers are discarded, so a single huge record does
+// not increase the memory usage permanently.
+const maxBufferSize = 64 << 10
+
+// bufPool is a pool of buffers.
+var bufPool = sync.Pool{
+	New: func() any {
+		b := make(buffer, 0, 1024)
+		return &b
+	},
+}
+
+// buffer is a byte slice used to format log lines.
+type buffer []byte
+
+// newBuffer returns an empty buffer from the pool.
+func newBuffer() *buffer {
+	return bufPool.Get().(*buffer)
+}
+
+// free returns the buffer to the pool.
+func (b *buffer) free() {
+	if cap(*b) > maxBufferSize {
+		return
+	}
+	*b = (*b)[:0]
+	bufPool.Put(b)
+}
+
+// Write appends p to the buffer. It always returns len(p) and a nil
+// error.
+func (b *buffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+// WriteString appends s to the buffer.
+func (b *buffer) WriteString(s string) {
+	*b = append(*b, s...)
+}
+
+// WriteByte appends c to the buffer. It always returns a nil error.
+func (b *buffer) WriteByte(c byte) error {
+	*b = append(*b, c)
+	return nil
+}
+
+// String returns the contents of the buffer as a string.
+func (b *buffer) String() string {
+	return string(*b)
+}
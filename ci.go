@@ -0,0 +1,326 @@
+package clilog
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// detectCIFormat returns the output format matching the CI system
+// the program is running on, according to the environment variables
+// set by it. It returns FormatText if no supported CI system is
+// detected.
+func detectCIFormat() OutputFormat {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return FormatGitHub
+	case os.Getenv("GITLAB_CI") != "":
+		return FormatGitLab
+	case os.Getenv("TF_BUILD") != "":
+		return FormatAzure
+	default:
+		return FormatText
+	}
+}
+
+// ciState is the state of the collapsible groups shared by a
+// handler and the handlers derived from it.
+type ciState struct {
+	sections []string // names of the open GitLab sections
+	n        int      // number of GitLab sections started
+}
+
+// appendAnnotation appends to b the record r rendered as an
+// annotation of the CI system selected by the output format. It
+// returns false, without appending anything, if the format does not
+// support annotations or the level of r does not have an associated
+// annotation.
+func (h *CLIHandler) appendAnnotation(b *buffer, r slog.Record) bool {
+	switch h.opts.Format {
+	case FormatGitHub:
+		return h.appendGitHubCommand(b, r)
+	case FormatAzure:
+		return h.appendAzureCommand(b, r)
+	default:
+		return false
+	}
+}
+
+// githubCommand returns the GitHub Actions workflow command used to
+// annotate the records with the provided level. It returns an empty
+// string if the records must be written as regular lines.
+func githubCommand(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= LevelNotice:
+		return "notice"
+	default:
+		return ""
+	}
+}
+
+// appendGitHubCommand appends to b the record r rendered as a GitHub
+// Actions workflow command. The source code position, if any, is
+// passed as the file and line of the annotation. It returns false,
+// without appending anything, if the level of r does not have an
+// associated command.
+func (h *CLIHandler) appendGitHubCommand(b *buffer, r slog.Record) bool {
+	cmd := githubCommand(r.Level)
+	if cmd == "" {
+		return false
+	}
+
+	b.WriteString("::" + cmd)
+	if src := h.annotationSource(r); src != nil {
+		b.WriteString(" file=")
+		appendGitHubProperty(b, githubPath(src.File))
+		b.WriteString(",line=")
+		*b = strconv.AppendInt(*b, int64(src.Line), 10)
+	}
+	b.WriteString("::")
+
+	msg := newBuffer()
+	defer msg.free()
+	h.appendAnnotationMessage(msg, r)
+	appendGitHubData(b, *msg)
+	return true
+}
+
+// azureIssueType returns the type of the Azure Pipelines issue used
+// to annotate the records with the provided level. It returns an
+// empty string if the records must be written as regular lines.
+func azureIssueType(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	default:
+		return ""
+	}
+}
+
+// appendAzureCommand appends to b the record r rendered as an Azure
+// Pipelines logging command. The source code position, if any, is
+// passed as the source path and line number of the issue. It returns
+// false, without appending anything, if the level of r does not have
+// an associated issue type.
+func (h *CLIHandler) appendAzureCommand(b *buffer, r slog.Record) bool {
+	typ := azureIssueType(r.Level)
+	if typ == "" {
+		return false
+	}
+
+	b.WriteString("##vso[task.logissue type=" + typ + ";")
+	if src := h.annotationSource(r); src != nil {
+		b.WriteString("sourcepath=")
+		appendAzureData(b, []byte(src.File), true)
+		b.WriteString(";linenumber=")
+		*b = strconv.AppendInt(*b, int64(src.Line), 10)
+		b.WriteByte(';')
+	}
+	b.WriteByte(']')
+
+	msg := newBuffer()
+	defer msg.free()
+	h.appendAnnotationMessage(msg, r)
+	appendAzureData(b, *msg, false)
+	return true
+}
+
+// annotationSource returns the source code position of r, or nil if
+// AddSource is not set or the position was replaced by ReplaceAttr
+// with a value of other type.
+func (h *CLIHandler) annotationSource(r slog.Record) *slog.Source {
+	v, ok := h.source(r)
+	if !ok || v.Kind() != slog.KindAny {
+		return nil
+	}
+	src, ok := v.Any().(*slog.Source)
+	if !ok || src.File == "" {
+		return nil
+	}
+	return src
+}
+
+// appendAnnotationMessage appends to b the message and the attributes
+// of r. Annotations are single lines without colors, so the
+// attributes rendered as blocks are appended after a newline, which
+// must be escaped by the caller.
+func (h *CLIHandler) appendAnnotationMessage(b *buffer, r slog.Record) {
+	blocks := newBuffer()
+	defer blocks.free()
+	h.appendMessage(b, r)
+	h.appendAttrs(&attrBuffer{line: b, blocks: blocks}, r)
+	if n := len(*blocks); n > 0 {
+		b.WriteByte('\n')
+		b.Write((*blocks)[:n-1])
+	}
+}
+
+// githubPath returns the path of file relative to the GitHub
+// workspace, so annotations can be linked to the files of the
+// repository. If the workspace is unknown, file is returned
+// unmodified.
+func githubPath(file string) string {
+	ws := os.Getenv("GITHUB_WORKSPACE")
+	if ws == "" {
+		return file
+	}
+	rel, err := filepath.Rel(ws, file)
+	if err != nil || !filepath.IsLocal(rel) {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// appendGitHubData appends s to b escaped as the data of a GitHub
+// Actions workflow command.
+func appendGitHubData(b *buffer, s []byte) {
+	for _, c := range s {
+		switch c {
+		case '%':
+			b.WriteString("%25")
+		case '\r':
+			b.WriteString("%0D")
+		case '\n':
+			b.WriteString("%0A")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// appendGitHubProperty appends s to b escaped as the value of a
+// property of a GitHub Actions workflow command.
+func appendGitHubProperty(b *buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '%':
+			b.WriteString("%25")
+		case '\r':
+			b.WriteString("%0D")
+		case '\n':
+			b.WriteString("%0A")
+		case ':':
+			b.WriteString("%3A")
+		case ',':
+			b.WriteString("%2C")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// appendAzureData appends s to b escaped as the data of an Azure
+// Pipelines logging command. If property is true, s is escaped as
+// the value of a property.
+func appendAzureData(b *buffer, s []byte, property bool) {
+	for _, c := range s {
+		switch {
+		case c == '%':
+			b.WriteString("%AZP25")
+		case c == '\r':
+			b.WriteString("%0D")
+		case c == '\n':
+			b.WriteString("%0A")
+		case property && c == ';':
+			b.WriteString("%3B")
+		case property && c == ']':
+			b.WriteString("%5D")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// gitlabSectionName returns a valid GitLab section name derived from
+// title. The sequence number n makes it unique.
+func gitlabSectionName(title string, n int) string {
+	name := make([]byte, 0, len(title)+8)
+	for i := 0; i < len(title); i++ {
+		switch c := title[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '-':
+			name = append(name, c)
+		default:
+			name = append(name, '_')
+		}
+	}
+	name = append(name, '_')
+	return string(strconv.AppendInt(name, int64(n), 10))
+}
+
+// StartGroup starts a collapsible group of log lines with the
+// provided title. Groups are rendered with FormatGitHub, FormatGitLab
+// and FormatAzure. With other formats, StartGroup does nothing.
+func (h *CLIHandler) StartGroup(title string) error {
+	b := newBuffer()
+	defer b.free()
+
+	switch h.opts.Format {
+	case FormatGitHub:
+		b.WriteString("::group::")
+		appendGitHubData(b, []byte(title))
+	case FormatGitLab:
+		h.mu.Lock()
+		h.ci.n++
+		name := gitlabSectionName(title, h.ci.n)
+		h.ci.sections = append(h.ci.sections, name)
+		h.mu.Unlock()
+
+		b.WriteString("\x1b[0Ksection_start:")
+		*b = strconv.AppendInt(*b, h.now().Unix(), 10)
+		b.WriteString(":" + name + "[collapsed=true]\r\x1b[0K")
+		b.WriteString(h.escape(title))
+	case FormatAzure:
+		b.WriteString("##[group]")
+		appendAzureData(b, []byte(title), false)
+	default:
+		return nil
+	}
+	return h.writeMarker(b)
+}
+
+// EndGroup ends the group started by the last call to StartGroup.
+// Groups are rendered with FormatGitHub, FormatGitLab and
+// FormatAzure. With other formats, EndGroup does nothing.
+func (h *CLIHandler) EndGroup() error {
+	b := newBuffer()
+	defer b.free()
+
+	switch h.opts.Format {
+	case FormatGitHub:
+		b.WriteString("::endgroup::")
+	case FormatGitLab:
+		h.mu.Lock()
+		n := len(h.ci.sections)
+		if n == 0 {
+			h.mu.Unlock()
+			return nil
+		}
+		name := h.ci.sections[n-1]
+		h.ci.sections = h.ci.sections[:n-1]
+		h.mu.Unlock()
+
+		b.WriteString("\x1b[0Ksection_end:")
+		*b = strconv.AppendInt(*b, h.now().Unix(), 10)
+		b.WriteString(":" + name + "\r\x1b[0K")
+	case FormatAzure:
+		b.WriteString("##[endgroup]")
+	default:
+		return nil
+	}
+	return h.writeMarker(b)
+}
+
+// writeMarker writes the group marker in b as a line of the output
+// of LevelInfo, the same way records are written.
+func (h *CLIHandler) writeMarker(b *buffer) error {
+	b.WriteByte('\n')
+	h.applyLineEnding(b)
+	return h.write(h.output(slog.LevelInfo), *b)
+}
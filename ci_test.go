@@ -0,0 +1,294 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+func TestCLIHandler_github(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *HandlerOptions
+		level slog.Level
+		msg   string
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name:  "error",
+			level: slog.LevelError,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `::error::message c=foo`,
+		},
+		{
+			name:  "warning",
+			level: slog.LevelWarn,
+			want:  `::warning::message`,
+		},
+		{
+			name:  "notice",
+			level: LevelNotice,
+			want:  `::notice::message`,
+		},
+		{
+			name:  "info",
+			level: slog.LevelInfo,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO message c=foo`,
+		},
+		{
+			name:  "escape",
+			level: slog.LevelError,
+			msg:   "100% done",
+			want:  `::error::100%25 done`,
+		},
+		{
+			name:  "blocks",
+			opts:  &HandlerOptions{Format: FormatGitHub, ErrorChain: true},
+			level: slog.LevelError,
+			attrs: []slog.Attr{slog.Any("err", fmt.Errorf("wrap: %w", errors.New("cause")))},
+			want:  `::error::message err="wrap: cause"%0A  err:%0A    caused by: cause`,
+		},
+		{
+			name:  "colors",
+			opts:  &HandlerOptions{Format: FormatGitHub, Color: ColorAlways},
+			level: slog.LevelError,
+			attrs: []slog.Attr{slog.Any("err", errors.New("fail"))},
+			want:  `::error::message err=fail`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if opts == nil {
+				opts = &HandlerOptions{Format: FormatGitHub}
+			}
+			opts.OmitTime = true
+			msg := tt.msg
+			if msg == "" {
+				msg = "message"
+			}
+
+			var buf bytes.Buffer
+			logger := slog.New(NewCLIHandler(&buf, opts))
+			logger.LogAttrs(context.Background(), tt.level, msg, tt.attrs...)
+
+			if got, want := buf.String(), tt.want+"\n"; got != want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_github_source(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	t.Setenv("GITHUB_WORKSPACE", filepath.Dir(file))
+
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{Format: FormatGitHub, AddSource: true}))
+	_, _, line, _ := runtime.Caller(0)
+	logger.Warn("message")
+
+	want := fmt.Sprintf("::warning file=ci_test.go,line=%v::message\n", line+1)
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestCLIHandler_StartGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		format OutputFormat
+		want   string
+	}{
+		{
+			name:   "github",
+			format: FormatGitHub,
+			want:   "::group::Build 1%25\nINFO message\n::endgroup::\n",
+		},
+		{
+			name:   "azure",
+			format: FormatAzure,
+			want:   "##[group]Build 1%AZP25\nINFO message\n##[endgroup]\n",
+		},
+		{
+			name:   "text",
+			format: FormatText,
+			want:   "INFO message\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := NewCLIHandler(&buf, &HandlerOptions{Format: tt.format, OmitTime: true})
+			if err := h.StartGroup("Build 1%"); err != nil {
+				t.Fatalf("start group error: %v", err)
+			}
+			slog.New(h).Info("message")
+			if err := h.EndGroup(); err != nil {
+				t.Fatalf("end group error: %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_StartGroup_gitlab(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{Format: FormatGitLab, OmitTime: true})
+	logger := slog.New(h)
+
+	h.StartGroup("Build all")
+	logger.Info("building")
+	h.StartGroup("Test")
+	logger.Info("testing")
+	h.EndGroup()
+	h.EndGroup()
+	h.EndGroup()
+
+	re := regexp.MustCompile(`:[0-9]+:`)
+	got := re.ReplaceAllString(buf.String(), ":TS:")
+	want := "\x1b[0Ksection_start:TS:Build_all_1[collapsed=true]\r\x1b[0KBuild all\n" +
+		"INFO building\n" +
+		"\x1b[0Ksection_start:TS:Test_2[collapsed=true]\r\x1b[0KTest\n" +
+		"INFO testing\n" +
+		"\x1b[0Ksection_end:TS:Test_2\r\x1b[0K\n" +
+		"\x1b[0Ksection_end:TS:Build_all_1\r\x1b[0K\n"
+	if got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestCLIHandler_StartGroup_Fallback(t *testing.T) {
+	var fallback bytes.Buffer
+	h := NewCLIHandler(errWriter{errors.New("broken pipe")}, &HandlerOptions{
+		Format:   FormatGitHub,
+		OmitTime: true,
+		Fallback: &fallback,
+	})
+
+	if err := h.StartGroup("Build"); err != nil {
+		t.Fatalf("start group error: %v", err)
+	}
+	slog.New(h).Info("message")
+	if err := h.EndGroup(); err != nil {
+		t.Fatalf("end group error: %v", err)
+	}
+
+	want := "::group::Build\nINFO message\n::endgroup::\n"
+	if got := fallback.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got := h.Errors(); got != 1 {
+		t.Errorf("unexpected number of errors: got: %v, want: 1", got)
+	}
+}
+
+func TestCLIHandler_azure(t *testing.T) {
+	tests := []struct {
+		name  string
+		level slog.Level
+		msg   string
+		want  string
+	}{
+		{
+			name:  "error",
+			level: slog.LevelError,
+			msg:   "message",
+			want:  `##vso[task.logissue type=error;]message c=foo`,
+		},
+		{
+			name:  "warning",
+			level: slog.LevelWarn,
+			msg:   "100%",
+			want:  `##vso[task.logissue type=warning;]100%AZP25 c=foo`,
+		},
+		{
+			name:  "notice",
+			level: LevelNotice,
+			msg:   "message",
+			want:  `NOTICE message c=foo`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{Format: FormatAzure, OmitTime: true}))
+			logger.Log(context.Background(), tt.level, tt.msg, "c", "foo")
+
+			if got, want := buf.String(), tt.want+"\n"; got != want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_azure_source(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{Format: FormatAzure, AddSource: true}))
+	_, file, line, _ := runtime.Caller(0)
+	logger.Error("message")
+
+	want := fmt.Sprintf("##vso[task.logissue type=error;sourcepath=%v;linenumber=%v;]message\n", file, line+1)
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestDetectCIFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want OutputFormat
+	}{
+		{
+			name: "github",
+			env:  map[string]string{"CI": "true", "GITHUB_ACTIONS": "true"},
+			want: FormatGitHub,
+		},
+		{
+			name: "gitlab",
+			env:  map[string]string{"CI": "true", "GITLAB_CI": "true"},
+			want: FormatGitLab,
+		},
+		{
+			name: "azure",
+			env:  map[string]string{"TF_BUILD": "True"},
+			want: FormatAzure,
+		},
+		{
+			name: "other",
+			env:  map[string]string{"CI": "true"},
+			want: FormatText,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "TF_BUILD"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			if got := detectCIFormat(); got != tt.want {
+				t.Errorf("unexpected format: got: %v, want: %v", got, tt.want)
+			}
+			h := NewCLIHandler(&bytes.Buffer{}, &HandlerOptions{Format: FormatCI})
+			if h.opts.Format != tt.want {
+				t.Errorf("unexpected handler format: got: %v, want: %v", h.opts.Format, tt.want)
+			}
+		})
+	}
+}
@@ -3,24 +3,41 @@ package clilog
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"log/slog"
-	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // CLIHandler implements a [slog.Handler] for command line tools. The
 // output format of CLIHandler is designed to be human readable.
+//
+// Each record is written with exactly one call to the Write method of
+// its writer, including the multi-line blocks, the continuation lines
+// and the escape sequences that replace progress lines. Records are
+// not interleaved when several processes write to the same pipe,
+// provided that the writes are atomic (e.g. shorter than PIPE_BUF on
+// POSIX systems) and w does not buffer them.
 type CLIHandler struct {
-	opts  HandlerOptions
-	group string // preformatted group, ends with a dot
-	attrs string // preformatted attrs, begins with a white space
+	opts     HandlerOptions
+	levels   map[slog.Level]string // level names
+	rules    []LevelRule           // level rules matching groups
+	pkgRules bool                  // whether rules depend on the package
+	tmpl     []templatePart        // compiled Template, if any
+	prefix   string                // bracketed prefixes of the message
+	inline   bool                  // whether blocks are rendered inline
+	start    time.Time             // creation time, used by TimeElapsed
+	groups   []string              // groups from WithGroup
+	attrs    preformatted          // preformatted attrs without colors
+	cattrs   preformatted          // preformatted attrs with colors
 
-	mu sync.Mutex
-	w  io.Writer
+	mu    sync.Locker   // serializes writes and protects state updates and ci
+	state *handlerState // settings that can be changed at runtime
+	ci    *ciState      // state of the CI groups
 }
 
 // HandlerOptions are options for a [CLIHandler]. A zero HandlerOptions
@@ -30,101 +47,1199 @@ type HandlerOptions struct {
 	// position of the log statement.
 	AddSource bool
 
+	// SourceFormat controls how the source code position is
+	// rendered when AddSource is set. The default is SourceLong.
+	SourceFormat SourceFormat
+
+	// SourceFunc causes the handler to include the name of the
+	// function in the source code position.
+	SourceFunc bool
+
+	// SourceLinks causes the handler to render the source code
+	// position as an OSC 8 hyperlink to the file when the output
+	// is a terminal. Terminals supporting hyperlinks allow to open
+	// the file by clicking on it.
+	SourceLinks bool
+
 	// Level reports the minimum record level that will be logged.
 	// The handler discards records with lower levels. If Level is
 	// nil, the handler assumes LevelInfo. The handler calls
 	// Level.Level for each record processed; to adjust the
 	// minimum level dynamically, use a LevelVar.
 	Level slog.Leveler
+
+	// SplitLevel is the minimum level of the records written to
+	// stderr by a handler created with NewCLIHandlerSplit. If
+	// SplitLevel is nil, the handler assumes LevelWarn.
+	SplitLevel slog.Leveler
+
+	// WriterFor returns the writer of the records with the
+	// provided level, allowing to route each level to a different
+	// writer (e.g. debug records to a file and the rest to the
+	// terminal). If it returns nil, the records are written to the
+	// writer of the handler, or to stderr according to SplitLevel.
+	// WriterFor is called once per level, the first time a record
+	// with that level is handled, and the result is reused by the
+	// handler and the handlers derived from it. Colors are enabled
+	// independently for each writer.
+	WriterFor func(level slog.Level) io.Writer
+
+	// LevelRules overrides Level for the records logged under
+	// specific groups or from specific packages. If several rules
+	// match a record, the most specific one is applied.
+	LevelRules []LevelRule
+
+	// ReplaceAttr is called to rewrite each non-group attribute
+	// before it is logged. The attribute's value has been resolved
+	// (see [slog.Value.Resolve]). If ReplaceAttr returns a zero
+	// Attr, the attribute is discarded.
+	//
+	// The built-in attributes with keys "time", "level", "source",
+	// and "msg" are passed to this function with a nil groups
+	// argument. Their keys are not part of the output, but their
+	// values are. The "source" attribute has a value of type
+	// *[slog.Source].
+	//
+	// The first argument is a list of currently open groups that
+	// contain the Attr. It must not be retained or modified.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+	// ContextAttrs is called by Handle with the context passed to
+	// it. The returned attributes are added to the record, after
+	// its own attributes, so the values stored in the context
+	// (e.g. request IDs) are logged without deriving loggers with
+	// With. They are processed as the rest of attributes of the
+	// record.
+	ContextAttrs func(ctx context.Context) []slog.Attr
+
+	// RedactKeys is a list of glob patterns (see [path.Match]).
+	// The values of the attributes whose key matches any of them
+	// are replaced with "[REDACTED]" before calling ReplaceAttr.
+	// Patterns are matched case-insensitively against both the key
+	// and the key qualified by its groups (e.g.
+	// "http.headers.authorization"). If the key of a group
+	// matches, the whole group is redacted.
+	RedactKeys []string
+
+	// DropKeys is a list of glob patterns, matched like those of
+	// RedactKeys. The attributes whose key matches any of them
+	// are discarded before calling ReplaceAttr. If the key of a
+	// group matches, the whole group is discarded.
+	DropKeys []string
+
+	// OnlyKeys is a list of glob patterns, matched like those of
+	// RedactKeys. If it is not empty, only the attributes whose
+	// key matches any of them, or that belong to a group whose key
+	// matches any of them, are rendered (e.g. "http" or
+	// "http.headers.*"). The rest are discarded before calling
+	// ReplaceAttr.
+	OnlyKeys []string
+
+	// DimKeys is a list of attribute keys. The attributes whose key
+	// is in the list are rendered with the style Theme.Dim. It
+	// allows to deemphasize attributes of little interest for
+	// humans, such as trace IDs. Keys are not qualified by groups.
+	DimKeys []string
+
+	// RenameKeys maps attribute keys to the keys displayed in their
+	// place. Both keys and group-qualified keys (e.g.
+	// "http.request.method") are accepted. A qualified key is
+	// displayed as is, without its groups, while a bare key keeps
+	// the groups of the attribute. Qualified keys take precedence.
+	// Renaming only affects how attributes are displayed: the keys
+	// seen by ReplaceAttr and matched by RedactKeys, DropKeys,
+	// OnlyKeys and DimKeys are the original ones.
+	RenameKeys map[string]string
+
+	// StripPrefixes is a list of group prefixes (e.g.
+	// "http.request") removed from the displayed keys of the
+	// attributes within those groups. The first matching prefix is
+	// stripped. Like RenameKeys, it only affects how attributes are
+	// displayed.
+	StripPrefixes []string
+
+	// RevealSecrets is the number of trailing characters of the
+	// values of type Secret that are revealed. It is intended for
+	// debugging. By default, secrets are completely masked.
+	RevealSecrets int
+
+	// Color controls whether the handler colorizes its output
+	// using ANSI escape sequences. With ColorAuto, colors are
+	// enabled if the writer is a terminal and the environment does
+	// not say otherwise (see ColorEnabled). Tools that provide
+	// their own color flag (e.g. --color) can map it to
+	// ColorAlways or ColorNever. The default is ColorNever.
+	Color ColorMode
+
+	// Theme defines the styles used when colors are enabled. If
+	// Theme is nil, the handler uses DefaultTheme.
+	Theme *Theme
+
+	// TimeFormat is the layout used to format timestamps (see
+	// [time.Time.Format]). It also accepts the special values
+	// TimeUnix, TimeUnixMilli, TimeUnixMicro and TimeUnixNano,
+	// which format timestamps as Unix time, and TimeElapsed. If
+	// TimeFormat is empty, the handler uses [time.RFC3339].
+	TimeFormat string
+
+	// TimePrecision is the precision of the timestamps. It
+	// replaces the fractional seconds of TimeFormat, adding them
+	// if needed (e.g. PrecisionMilliseconds turns time.RFC3339 into
+	// "2006-01-02T15:04:05.000Z07:00"), and sets the number of
+	// decimals of TimeElapsed. It does not affect the Unix time
+	// formats. By default, the precision of TimeFormat is used.
+	TimePrecision TimePrecision
+
+	// TimeZone is the location in which timestamps are rendered,
+	// regardless of the location of the time of the records. If
+	// TimeZone is nil, the location of the time of each record is
+	// used, which is usually the local time zone.
+	TimeZone *time.Location
+
+	// UseUTC causes the handler to render timestamps in UTC. It is
+	// equivalent to setting TimeZone to [time.UTC], which takes
+	// precedence if set.
+	UseUTC bool
+
+	// OmitTime causes the handler to omit the timestamp.
+	OmitTime bool
+
+	// Now returns the current time. If set, it replaces the time
+	// of the records and the clock used by TimeElapsed and the CI
+	// groups, so the output can be deterministic. Records with a
+	// zero time are still rendered without timestamp, unless
+	// StampZero is set. If Now is nil, the handler uses [time.Now].
+	Now func() time.Time
+
+	// StampZero causes the handler to set the time of the records
+	// with a zero time to the current time, according to Now, so
+	// hand-constructed and replayed records are rendered with a
+	// timestamp like the rest.
+	StampZero bool
+
+	// Quote controls how attribute values are quoted. The default
+	// is QuoteWhenNeeded.
+	Quote QuoteMode
+
+	// NoEscape causes the handler to write control characters in
+	// messages, keys and unquoted values verbatim. By default,
+	// they are replaced with Go escape sequences (e.g. "\n" or
+	// "\x1b"), so a hostile value cannot forge additional log
+	// lines or send control sequences to the terminal.
+	NoEscape bool
+
+	// KVSeparator is written between the key and the value of each
+	// attribute (e.g. ": "). If KVSeparator is empty, the handler
+	// uses "=".
+	KVSeparator string
+
+	// AttrSeparator is written before each attribute, separating
+	// it from the message and from the previous attribute (e.g.
+	// "\t" for tab-separated fields). If AttrSeparator is empty,
+	// the handler uses a single space.
+	AttrSeparator string
+
+	// MaxValueLen is the maximum number of characters of the
+	// attribute values rendered as strings. Longer values are
+	// truncated and suffixed with the number of bytes omitted
+	// (e.g. "…(+1234 bytes)"). If MaxValueLen is zero, values are
+	// not truncated.
+	MaxValueLen int
+
+	// MaxAttrs is the maximum number of attributes of a record
+	// that are rendered. The attributes added with WithAttrs are
+	// not counted, nor are the attributes discarded by DropKeys,
+	// OnlyKeys or ReplaceAttr. If a record has more attributes,
+	// the rest are omitted and replaced with the marker
+	// "…truncated", or the attribute truncated=true in logfmt
+	// lines. If MaxAttrs is zero, all the attributes are rendered.
+	MaxAttrs int
+
+	// MaxLineBytes is the maximum size in bytes of a rendered
+	// record, including its multi-line blocks and excluding the
+	// line ending. Longer records are truncated and suffixed with
+	// the marker "…truncated", or the attribute truncated=true in
+	// logfmt lines, protecting terminals and files from huge
+	// lines. If MaxLineBytes is zero, records are not truncated.
+	MaxLineBytes int
+
+	// FloatFormat is the fmt format used to render float values
+	// (e.g. "%.3f"). If FloatFormat is empty, floats are rendered
+	// with the minimum number of digits that represents them
+	// exactly.
+	FloatFormat string
+
+	// ExpThreshold is the decimal exponent from which float values
+	// are rendered in scientific notation. That is, floats whose
+	// absolute value is greater than or equal to 10^ExpThreshold,
+	// or lower than 10^-ExpThreshold, are rendered like 1.5e+06
+	// and the rest like 1500.25. If ExpThreshold is zero, the
+	// threshold of [strconv.FormatFloat] with the 'g' format is
+	// used. ExpThreshold is ignored if FloatFormat is set.
+	ExpThreshold int
+
+	// DigitSeparator is inserted between every three digits of
+	// integer values (e.g. "," renders 1234567 as 1,234,567). If
+	// DigitSeparator is empty, digits are not grouped. It is
+	// ignored by the logfmt format.
+	DigitSeparator string
+
+	// AnyFormat controls how the values of kind slog.KindAny are
+	// rendered. The default is AnyJSON.
+	AnyFormat AnyFormat
+
+	// DisableStringer prevents the handler from calling the String
+	// method of the attribute values, which may panic or block
+	// (e.g. if it acquires a lock held by the caller). Values are
+	// rendered using reflection instead.
+	DisableStringer bool
+
+	// Multiline causes the handler to render values containing
+	// newlines (e.g. stack traces or command output) as indented
+	// blocks below the log line, instead of escaping them.
+	Multiline bool
+
+	// ErrorChain causes the handler to render the chain of errors
+	// wrapped by attribute values of type error (see
+	// [errors.Unwrap]) as an indented block below the log line.
+	ErrorChain bool
+
+	// LevelNames maps levels to the names used to render them. It
+	// allows abbreviations (e.g. "WRN"), localized names or names
+	// for custom levels. Levels not present in the map are
+	// rendered by [slog.Level.String]. The levels are evaluated
+	// when the handler is created.
+	LevelNames map[slog.Leveler]string
+
+	// LevelWidth is the minimum width of the level token. Shorter
+	// level names are padded with spaces, so messages are aligned
+	// across lines.
+	LevelWidth int
+
+	// MessageWidth is the minimum width of the message. Shorter
+	// messages are padded with spaces, so the attributes are
+	// aligned across lines.
+	MessageWidth int
+
+	// ProgressInterval is the minimum interval between the lines
+	// written for the progress records (see Progress) with the
+	// same message when the output is not a terminal. If it is
+	// not positive, the handler uses DefaultProgressInterval.
+	ProgressInterval time.Duration
+
+	// Prefix is a tag prepended to every message in brackets
+	// (e.g. "[build] message"). It allows to scope the output of
+	// the phases or subcommands of a program. See also WithPrefix.
+	Prefix string
+
+	// KeyOrder defines the order in which the attributes are
+	// rendered. By default, they are rendered in the order they
+	// were added.
+	KeyOrder KeyOrder
+
+	// Expanded causes the handler to render the attributes below
+	// the log line, one per line, with the attributes of groups
+	// indented under the name of the group. It is intended for
+	// verbose output of complex records. GroupStyle is ignored.
+	Expanded bool
+
+	// GroupStyle controls how the attributes within groups are
+	// rendered. The default is GroupsDotted.
+	GroupStyle GroupStyle
+
+	// Icons controls whether the level is prefixed or replaced by
+	// an icon. The default is IconsOff.
+	Icons IconMode
+
+	// IconSet defines the icons used when Icons is not IconsOff.
+	// If IconSet is nil, the handler uses DefaultIcons.
+	IconSet *IconSet
+
+	// Format controls the format of the log lines. The default is
+	// FormatText. With FormatLogfmt, the options that only affect
+	// human readable output are ignored. FormatAuto selects the
+	// format depending on whether the output is a terminal.
+	Format OutputFormat
+
+	// Overflow controls how lines wider than Width are rendered.
+	// The default is OverflowNone.
+	Overflow OverflowMode
+
+	// Width is the maximum width of the log lines, used when
+	// Overflow is not OverflowNone. If Width is zero, the width of
+	// the terminal is used, and the lines written to writers other
+	// than terminals are not modified.
+	Width int
+
+	// Layout controls the order of the components of the log
+	// line. The default is TimeFirst.
+	Layout Layout
+
+	// Delta controls whether and where the time elapsed since the
+	// previous record is rendered (e.g. "(+230ms)"), styled with
+	// Theme.Dim, so slow steps stand out. The first record is
+	// compared with the creation time of the handler. Deltas are
+	// not rendered in logfmt lines, CI annotations and templates.
+	Delta DeltaMode
+
+	// Template, if not empty, overrides Layout with a custom
+	// layout (e.g. "{level} {time} {source} {msg}{attrs}"). The
+	// placeholders {time}, {level}, {source}, {msg} and {attrs}
+	// are replaced with the corresponding components of the
+	// record. Components missing from the template are not
+	// rendered, and whitespace following a component that renders
+	// nothing is omitted. Every attribute is preceded by
+	// AttrSeparator. Other text, including unknown placeholders,
+	// is written verbatim. The template is compiled when the
+	// handler is created.
+	Template string
+
+	// OnError is called with the errors returned by the writers of
+	// the handler, which are also returned by Handle but ignored by
+	// [slog.Logger]. It allows programs to react to broken pipes
+	// or full disks, for example by exiting or calling SetOutput.
+	// OnError is called synchronously from the goroutine that
+	// handles the record, after the lock serializing writes has
+	// been released. The number of errors is returned by the
+	// Errors method.
+	OnError func(err error)
+
+	// Fallback is the writer used by the handler after a write to
+	// its output fails, for example because the pager reading it
+	// exited or the disk is full. The record that could not be
+	// written is written to Fallback, which is used from then on
+	// by the handler and the handlers derived from it. Fallback
+	// is never replaced. If Fallback is nil, the output is never
+	// replaced.
+	Fallback io.Writer
+
+	// FallbackNotice causes the handler to write a warning with
+	// the error to Fallback before switching to it.
+	FallbackNotice bool
+
+	// StopOnBrokenPipe causes the handler to stop writing to an
+	// output once a write fails because it is a pipe whose reader
+	// exited, which is expected when the output of a program is
+	// piped into commands like head. The records are discarded
+	// from then on, without errors. The broken pipe error is only
+	// passed to OnError. If Fallback is set, it takes precedence.
+	StopOnBrokenPipe bool
+
+	// LineEnding controls how the lines of the records are
+	// terminated. The default is LineEndingLF.
+	LineEnding LineEnding
+
+	// Lock serializes the writes of the handler and the handlers
+	// derived from it, and the changes of their settings. Sharing
+	// a Lock among handlers writing to the same file (e.g. the
+	// stdout and stderr of a terminal) prevents their lines from
+	// being interleaved. If the caller already serializes logging
+	// and the changes of the settings, it can be set to NoLock. If
+	// Lock is nil, the handler uses its own mutex.
+	Lock sync.Locker
 }
 
+// Special values of [HandlerOptions.TimeFormat].
+const (
+	// TimeUnix formats timestamps as the number of seconds
+	// elapsed since January 1, 1970 UTC.
+	TimeUnix = "unix"
+
+	// TimeUnixMilli formats timestamps as the number of
+	// milliseconds elapsed since January 1, 1970 UTC.
+	TimeUnixMilli = "unixmilli"
+
+	// TimeUnixMicro formats timestamps as the number of
+	// microseconds elapsed since January 1, 1970 UTC.
+	TimeUnixMicro = "unixmicro"
+
+	// TimeUnixNano formats timestamps as the number of
+	// nanoseconds elapsed since January 1, 1970 UTC.
+	TimeUnixNano = "unixnano"
+
+	// TimeElapsed formats timestamps as the time elapsed since
+	// the creation of the handler (e.g. "+12.450s").
+	TimeElapsed = "elapsed"
+)
+
 // NewCLIHandler returns a new [CLIHandler].
 func NewCLIHandler(w io.Writer, opts *HandlerOptions) *CLIHandler {
 	if opts == nil {
 		opts = &HandlerOptions{}
 	}
-	return &CLIHandler{
-		opts: *opts,
-		w:    w,
+	h := &CLIHandler{
+		opts:   *opts,
+		levels: levelNames(opts.LevelNames),
+		mu:     opts.Lock,
+		ci:     &ciState{},
+	}
+	if h.mu == nil {
+		h.mu = &sync.Mutex{}
+	}
+	h.start = h.now()
+	h.opts.Format = resolveFormat(w, h.opts.Format)
+	switch h.opts.Format {
+	case FormatLogfmt:
+		h.opts = logfmtOptions(h.opts)
+	case FormatJournald:
+		h.opts = journaldOptions(h.opts)
+	}
+	h.state = &handlerState{color: h.opts.Color}
+	h.state.last.Store(h.start.UnixNano())
+	h.state.out.Store(newOutput(w, h.opts.Color))
+	h.rules, h.pkgRules = groupRules(h.opts.LevelRules, nil)
+	if h.opts.Template != "" {
+		h.tmpl = parseTemplate(h.opts.Template)
+	}
+	if h.opts.Prefix != "" {
+		h.prefix = bracketPrefix(h.opts.Prefix)
+	}
+	if h.opts.Theme == nil {
+		h.opts.Theme = &DefaultTheme
+	}
+	if h.opts.IconSet == nil {
+		h.opts.IconSet = &DefaultIcons
+	}
+	if h.opts.TimeFormat == "" {
+		h.opts.TimeFormat = time.RFC3339
+	}
+	switch h.opts.TimeFormat {
+	case TimeUnix, TimeUnixMilli, TimeUnixMicro, TimeUnixNano, TimeElapsed:
+	default:
+		h.opts.TimeFormat = layoutPrecision(h.opts.TimeFormat, h.opts.TimePrecision)
+	}
+	if h.opts.TimeZone == nil && h.opts.UseUTC {
+		h.opts.TimeZone = time.UTC
+	}
+	if h.opts.KVSeparator == "" {
+		h.opts.KVSeparator = "="
+	}
+	if h.opts.AttrSeparator == "" {
+		h.opts.AttrSeparator = " "
 	}
+	return h
 }
 
 // Enabled reports whether the handler handles records at the given
 // level. The handler ignores records whose level is lower.
 func (h *CLIHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	minLevel := slog.LevelInfo
-	if h.opts.Level != nil {
-		minLevel = h.opts.Level.Level()
-	}
-	return level >= minLevel
+	return level >= h.lowestLevel()
 }
 
 // Handle handles the Record.
 func (h *CLIHandler) Handle(ctx context.Context, r slog.Record) error {
-	var b strings.Builder
-	if !r.Time.IsZero() {
-		b.WriteString(r.Time.Format(time.RFC3339) + " ")
-	}
-	b.WriteString(r.Level.String() + " ")
-	if h.opts.AddSource && r.PC != 0 {
-		fs := runtime.CallersFrames([]uintptr{r.PC})
-		f, _ := fs.Next()
-		fmt.Fprintf(&b, "%v:%v ", f.File, f.Line)
-	}
-	b.WriteString(r.Message)
-	b.WriteString(h.attrs)
-	r.Attrs(func(a slog.Attr) bool {
-		h.appendAttr(&b, h.group, a)
-		return true
-	})
-	b.WriteString("\n")
+	if h.pkgRules && r.Level < h.minLevel(r.PC) {
+		return nil
+	}
+
+	h.state.counts[levelIndex(r.Level)].Add(1)
+
+	switch {
+	case r.Time.IsZero():
+		if h.opts.StampZero {
+			r.Time = h.now()
+		}
+	case h.opts.Now != nil:
+		r.Time = h.opts.Now()
+	}
+	if h.prefix != "" {
+		r.Message = h.prefix + r.Message
+	}
+	if h.opts.ContextAttrs != nil {
+		if attrs := h.opts.ContextAttrs(ctx); len(attrs) > 0 {
+			// The record may share its attributes with the
+			// caller's copy.
+			r = r.Clone()
+			r.AddAttrs(attrs...)
+		}
+	}
+
+	out := h.output(r.Level)
+
+	b := newBuffer()
+	defer b.free()
+
+	blocks := newBuffer()
+	defer blocks.free()
+	buf := attrBuffer{line: b, blocks: blocks, color: out.color}
+	if h.opts.Format == FormatJournald {
+		appendJournalPriority(b, r.Level)
+	}
+	h.appendSectionIndent(b)
+	switch {
+	case h.opts.Format == FormatLogfmt:
+		h.appendLogfmtHeader(b, out, r)
+		h.appendAttrs(&buf, r)
+	case h.appendAnnotation(b, r):
+		// The record has been rendered as a CI annotation.
+	case h.tmpl != nil:
+		h.appendTemplate(&buf, out, r)
+	default:
+		h.appendHeader(&buf, out, r)
+		h.appendAttrs(&buf, r)
+		if h.opts.Delta == DeltaSuffix && !r.Time.IsZero() {
+			b.WriteByte(' ')
+			h.appendDelta(b, out, r, false)
+		}
+	}
+	if h.opts.Overflow != OverflowNone {
+		h.fitLine(&buf, out)
+	}
+	b.WriteByte('\n')
+	b.Write(*blocks)
+	if h.opts.MaxLineBytes > 0 {
+		truncateRecord(b, h.opts.MaxLineBytes, h.opts.Format == FormatLogfmt)
+	}
+	if h.opts.LineEnding != LineEndingLF {
+		h.applyLineEnding(b)
+	}
 
+	if p, ok := progressOf(r); ok {
+		return h.writeProgress(out, r, p, *b)
+	}
+	return h.write(out, *b)
+}
+
+// write writes p to out. Writes are serialized across the handlers
+// sharing the outputs of h. p must contain a whole record, since
+// records are written with exactly one call to Write.
+func (h *CLIHandler) write(out *output, p []byte) error {
+	return h.writeLine(out, p, false)
+}
+
+// writeLine writes p to out. If inPlace is true, p is a progress line
+// without trailing newline that is replaced by the next line written
+// to a terminal.
+func (h *CLIHandler) writeLine(out *output, p []byte, inPlace bool) error {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-	_, err := h.w.Write([]byte(b.String()))
+	if h.state.progress.inPlace && out.tty {
+		// The escape sequence is written with p, so the record
+		// is still written with a single call to Write.
+		b := newBuffer()
+		defer b.free()
+		b.WriteString(clearLine)
+		b.Write(p)
+		p = *b
+	}
+	_, err := out.w.Write(p)
+	if out.tty {
+		h.state.progress.inPlace = inPlace && err == nil
+	}
+	var (
+		fallback *output
+		stopped  bool
+	)
+	if err != nil {
+		fallback = h.failover(out)
+		stopped = fallback == nil && h.stopOutput(out, err)
+	}
+	h.mu.Unlock()
+	h.reportError(err)
+	if stopped {
+		return nil
+	}
+	if fallback == nil {
+		return err
+	}
+
+	if h.opts.FallbackNotice {
+		r := slog.NewRecord(h.now(), slog.LevelWarn, "log output failed, switching to fallback", 0)
+		r.AddAttrs(slog.Any("err", err))
+		h.Handle(context.Background(), r) //nolint:errcheck
+	}
+	return h.write(fallback, p)
+}
+
+// reportError counts err and passes it to OnError, if it is not nil.
+// It returns err. It must not be called with h.mu held, so OnError
+// can reconfigure the handler.
+func (h *CLIHandler) reportError(err error) error {
+	if err == nil {
+		return nil
+	}
+	h.state.errors.Add(1)
+	if h.opts.OnError != nil {
+		h.opts.OnError(err)
+	}
 	return err
 }
 
 // WithAttrs returns a new Handler whose attributes consist of both
 // the receiver's attributes and the arguments.
 func (h *CLIHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	var b strings.Builder
+	if len(attrs) == 0 {
+		return h
+	}
+	// Attributes are processed once, but formatted with and
+	// without colors, so the handler can write to outputs with
+	// different color settings.
+	buf, cbuf := newAttrBuffer(false), newAttrBuffer(true)
+	defer buf.free()
+	defer cbuf.free()
+	buf.open = slices.Clone(h.attrs.open)
+	cbuf.open = slices.Clone(h.cattrs.open)
+	processed := make([]slog.Attr, 0, len(attrs))
 	for _, a := range attrs {
-		h.appendAttr(&b, h.group, a)
+		processed = append(processed, h.processAttr(h.groups, a))
 	}
-	return &CLIHandler{
-		opts:  h.opts,
-		group: h.group,
-		attrs: h.attrs + b.String(),
-		w:     h.w,
+	if h.opts.KeyOrder.enabled() {
+		h.opts.KeyOrder.sort(processed)
 	}
+	for _, a := range processed {
+		h.formatAttr(&buf, h.groups, a)
+		h.formatAttr(&cbuf, h.groups, a)
+	}
+	h2 := h.clone()
+	h2.attrs = h.attrs.add(&buf)
+	h2.cattrs = h.cattrs.add(&cbuf)
+	return h2
 }
 
 // WithGroup returns a new Handler with the given group appended to
-// the receiver's existing groups.
+// the receiver's existing groups. If name is empty, WithGroup returns
+// the receiver.
 func (h *CLIHandler) WithGroup(name string) slog.Handler {
-	return &CLIHandler{
-		opts:  h.opts,
-		group: h.group + name + ".",
-		attrs: h.attrs,
-		w:     h.w,
+	if name == "" {
+		return h
 	}
+	h2 := h.clone()
+	h2.groups = slices.Clip(append(h.groups, name))
+	h2.rules, h2.pkgRules = groupRules(h.opts.LevelRules, h2.groups)
+	return h2
 }
 
-func (h *CLIHandler) appendAttr(w io.Writer, group string, a slog.Attr) {
-	if a.Equal(slog.Attr{}) {
-		return
+// clone returns a shallow copy of the handler. The returned handler
+// shares its outputs with h.
+func (h *CLIHandler) clone() *CLIHandler {
+	h2 := *h
+	return &h2
+}
+
+// preformatted contains formatted attributes. Its contents are never
+// modified once created, so they can be shared by derived handlers.
+type preformatted struct {
+	line   []byte   // attributes rendered in the log line
+	blocks []byte   // attributes rendered as multi-line blocks
+	cuts   []int    // offsets of the attributes in line, if needed
+	open   []string // bracketed groups left open at the end of line
+}
+
+// add returns the result of appending the contents of buf to p. p is
+// not modified.
+func (p preformatted) add(buf *attrBuffer) preformatted {
+	cuts := slices.Clip(p.cuts)
+	for _, c := range buf.cuts {
+		cuts = append(cuts, len(p.line)+c)
+	}
+	return preformatted{
+		line:   concat(p.line, *buf.line),
+		blocks: concat(p.blocks, *buf.blocks),
+		cuts:   cuts,
+		open:   slices.Clone(buf.open),
+	}
+}
+
+// concat returns a new slice with the contents of a followed by the
+// contents of b. If b is empty, a is returned.
+func concat(a, b []byte) []byte {
+	if len(b) == 0 {
+		return a
+	}
+	c := make([]byte, 0, len(a)+len(b))
+	return append(append(c, a...), b...)
+}
+
+// attrBuffer accumulates formatted attributes.
+type attrBuffer struct {
+	line   *buffer  // attributes rendered in the log line
+	blocks *buffer  // attributes rendered as multi-line blocks
+	color  bool     // whether styles are applied
+	msg    int      // offset of the message in line
+	cuts   []int    // offsets of the attributes in line, if wrapping
+	pad    int      // pending padding after the message
+	open   []string // bracketed groups open in line
+	first  bool     // whether a bracketed group has just been opened
+}
+
+// flushPad writes the pending padding after the message.
+func (buf *attrBuffer) flushPad() {
+	for ; buf.pad > 0; buf.pad-- {
+		buf.line.WriteByte(' ')
+	}
+}
+
+// newAttrBuffer returns an attrBuffer backed by pooled buffers.
+func newAttrBuffer(color bool) attrBuffer {
+	return attrBuffer{line: newBuffer(), blocks: newBuffer(), color: color}
+}
+
+// free returns the buffers of buf to the pool.
+func (buf *attrBuffer) free() {
+	buf.line.free()
+	buf.blocks.free()
+}
+
+// style returns the provided style if colors are enabled. Otherwise,
+// it returns an empty string.
+func (buf *attrBuffer) style(style string) string {
+	if !buf.color {
+		return ""
 	}
+	return style
+}
 
+// appendAttrs appends to buf the preformatted attributes of the
+// handler followed by the attributes of r. It reports whether
+// anything was appended to the log line.
+func (h *CLIHandler) appendAttrs(buf *attrBuffer, r slog.Record) bool {
+	pre := h.attrs
+	if buf.color {
+		pre = h.cattrs
+	}
+	if len(pre.line) > 0 {
+		buf.flushPad()
+	}
+	n := len(*buf.line)
+	for _, c := range pre.cuts {
+		buf.cuts = append(buf.cuts, n+c)
+	}
+	buf.line.Write(pre.line)
+	buf.blocks.Write(pre.blocks)
+	if len(pre.open) > 0 {
+		// buf.open is modified, so it must not share memory
+		// with pre.open.
+		buf.open = append(buf.open[:0:0], pre.open...)
+	}
+	truncated := false
+	if h.opts.KeyOrder.enabled() {
+		// The attributes must be processed before sorting
+		// them, because ReplaceAttr can change their keys.
+		attrs := make([]slog.Attr, 0, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, h.processAttr(h.groups, a))
+			return true
+		})
+		h.opts.KeyOrder.sort(attrs)
+		if h.opts.MaxAttrs > 0 {
+			attrs, truncated = h.limitAttrs(attrs)
+		}
+		for _, a := range attrs {
+			h.formatAttr(buf, h.groups, a)
+		}
+	} else {
+		rendered := 0
+		r.Attrs(func(a slog.Attr) bool {
+			if h.opts.MaxAttrs > 0 && rendered == h.opts.MaxAttrs {
+				// The discarded attributes are not counted.
+				if !isEmptyAttr(h.processAttr(h.groups, a)) {
+					truncated = true
+					return false
+				}
+				return true
+			}
+			size := len(*buf.line) + len(*buf.blocks)
+			h.appendAttr(buf, h.groups, a)
+			if len(*buf.line)+len(*buf.blocks) > size {
+				rendered++
+			}
+			return true
+		})
+	}
+	if len(buf.open) > 0 {
+		h.enterGroups(buf, nil)
+	}
+	if truncated {
+		buf.flushPad()
+		h.appendSeparator(buf)
+		buf.line.WriteString(h.truncatedMarker())
+	}
+	return len(*buf.line) > n
+}
+
+// appendAttr processes the attribute a and appends it to buf. Unlike
+// processAttr, groups are processed and formatted one attribute at a
+// time, so no intermediate groups are allocated.
+func (h *CLIHandler) appendAttr(buf *attrBuffer, groups []string, a slog.Attr) {
+	a = h.processLeaf(groups, a)
 	if a.Value.Kind() != slog.KindGroup {
-		fmt.Fprintf(w, " %v%v=%v", group, a.Key, a.Value)
+		h.formatAttr(buf, groups, a)
 		return
 	}
-
 	if a.Key != "" {
-		group += a.Key + "."
+		groups = slices.Clip(append(groups, a.Key))
 	}
 	for _, a := range a.Value.Group() {
-		h.appendAttr(w, group, a)
+		h.appendAttr(buf, groups, a)
+	}
+}
+
+// processAttr resolves the attribute a and applies the redaction rules
+// and ReplaceAttr to it. If a is a group, its attributes are processed
+// recursively. It returns an empty Attr if a must be discarded.
+func (h *CLIHandler) processAttr(groups []string, a slog.Attr) slog.Attr {
+	if a = h.processLeaf(groups, a); a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+
+	if a.Key != "" {
+		groups = slices.Clip(append(groups, a.Key))
+	}
+	attrs := a.Value.Group()
+	processed := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a = h.processAttr(groups, a); !a.Equal(slog.Attr{}) {
+			processed = append(processed, a)
+		}
+	}
+	if h.opts.KeyOrder.enabled() {
+		h.opts.KeyOrder.sort(processed)
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(processed...)}
+}
+
+// processLeaf resolves the attribute a and applies the redaction rules
+// to it. If the resulting attribute is not a group, ReplaceAttr is
+// applied too. The attributes of groups are left untouched.
+func (h *CLIHandler) processLeaf(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindLogValuer {
+		if s, ok := a.Value.Any().(Secret); ok {
+			a.Value = slog.StringValue(s.reveal(h.opts.RevealSecrets))
+		}
+	}
+	a.Value = a.Value.Resolve()
+	if a.Key != "" && h.dropKey(groups, a.Key, a.Value.Kind() == slog.KindGroup) {
+		return slog.Attr{}
+	}
+	if a.Key != "" && matchKey(h.opts.RedactKeys, groups, a.Key) {
+		a.Value = slog.StringValue(redacted)
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		if rep := h.opts.ReplaceAttr; rep != nil {
+			a = rep(groups, a)
+			a.Value = a.Value.Resolve()
+		}
+	}
+	return a
+}
+
+// formatAttr formats the processed attribute a and appends it to buf.
+func (h *CLIHandler) formatAttr(buf *attrBuffer, groups []string, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = slices.Clip(append(groups, a.Key))
+		}
+		for _, a := range a.Value.Group() {
+			h.formatAttr(buf, groups, a)
+		}
+		return
+	}
+
+	// Calling Any on values of other kinds allocates.
+	var (
+		isStack, isErr bool
+		err            error
+	)
+	if a.Value.Kind() == slog.KindAny {
+		_, isStack = a.Value.Any().(stackTrace)
+		err, isErr = a.Value.Any().(error)
+	}
+	// logfmt, journald and system log lines cannot be followed
+	// by blocks.
+	isStack = isStack && !h.inline && h.opts.Format != FormatLogfmt && h.opts.Format != FormatJournald
+	isBlock := isStack || h.opts.Multiline && h.isMultiline(a.Value)
+
+	style := ""
+	switch {
+	case isErr:
+		style = buf.style(h.opts.Theme.ErrorAttr)
+	case len(h.opts.DimKeys) > 0 && slices.Contains(h.opts.DimKeys, a.Key):
+		style = buf.style(h.opts.Theme.Dim)
+	}
+
+	if len(h.opts.RenameKeys) > 0 || len(h.opts.StripPrefixes) > 0 {
+		groups, a.Key = h.displayKey(groups, a.Key)
+	}
+
+	if h.opts.Expanded {
+		h.enterGroups(buf, groups)
+		depth := len(groups)
+		if isBlock {
+			h.appendBlock(buf.blocks, depth, h.escape(a.Key), h.truncateValue(h.valueString(a.Value)))
+			return
+		}
+		b := buf.blocks
+		appendIndent(b, depth+1)
+		b.WriteString(style)
+		h.appendEscaped(b, a.Key)
+		b.WriteString(h.opts.KVSeparator)
+		h.appendValue(b, a.Value)
+		endStyle(b, style)
+		b.WriteByte('\n')
+		if isErr && h.opts.ErrorChain {
+			h.appendErrorChain(buf.blocks, h.keyString(groups, a.Key), err)
+		}
+		return
+	}
+
+	if isBlock {
+		h.appendBlock(buf.blocks, 0, h.keyString(groups, a.Key), h.truncateValue(h.valueString(a.Value)))
+		return
+	}
+
+	b := buf.line
+	buf.flushPad()
+	keyGroups := groups
+	if h.opts.GroupStyle == GroupsBracketed {
+		h.enterGroups(buf, groups)
+		keyGroups = nil
+	}
+	h.appendSeparator(buf)
+	b.WriteString(style)
+	h.appendKey(b, keyGroups, a.Key)
+	b.WriteString(h.opts.KVSeparator)
+	h.appendValue(b, a.Value)
+	endStyle(b, style)
+
+	if isErr && h.opts.ErrorChain {
+		h.appendErrorChain(buf.blocks, h.keyString(groups, a.Key), err)
+	}
+}
+
+// appendSeparator appends to buf the separator that precedes an
+// attribute, unless it is the first attribute within a bracketed
+// group.
+func (h *CLIHandler) appendSeparator(buf *attrBuffer) {
+	if buf.first {
+		buf.first = false
+		return
+	}
+	if h.opts.Overflow == OverflowWrap && len(buf.open) == 0 {
+		buf.cuts = append(buf.cuts, len(*buf.line))
+	}
+	buf.line.WriteString(h.opts.AttrSeparator)
+}
+
+// isMultiline reports whether the string representation of v spans
+// multiple lines.
+func (h *CLIHandler) isMultiline(v slog.Value) bool {
+	switch v.Kind() {
+	case slog.KindString, slog.KindAny, slog.KindLogValuer:
+		return strings.Contains(h.valueString(v), "\n")
+	default:
+		return false
+	}
+}
+
+// appendKey appends to b the escaped key qualified by groups.
+func (h *CLIHandler) appendKey(b *buffer, groups []string, key string) {
+	if h.opts.Format == FormatLogfmt {
+		appendLogfmtKey(b, groups, key)
+		return
+	}
+	for _, g := range groups {
+		h.appendEscaped(b, g)
+		b.WriteByte('.')
+	}
+	h.appendEscaped(b, key)
+}
+
+// keyString returns the escaped key qualified by groups.
+func (h *CLIHandler) keyString(groups []string, key string) string {
+	b := newBuffer()
+	defer b.free()
+	h.appendKey(b, groups, key)
+	return b.String()
+}
+
+// appendValue appends to b the string representation of v, quoted
+// according to the configured quoting mode.
+func (h *CLIHandler) appendValue(b *buffer, v slog.Value) {
+	switch v.Kind() {
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindBool, slog.KindDuration:
+		// The representation of these kinds never needs
+		// quoting.
+		if h.opts.Quote != QuoteAlways {
+			h.appendScalar(b, v)
+			return
+		}
+		b.WriteByte('"')
+		h.appendScalar(b, v)
+		b.WriteByte('"')
+	case slog.KindTime:
+		// The representation of times contains spaces.
+		if h.opts.Quote == QuoteNever {
+			*b = appendTimeString(*b, v.Time())
+			return
+		}
+		b.WriteByte('"')
+		*b = appendTimeString(*b, v.Time())
+		b.WriteByte('"')
+	default:
+		h.appendString(b, h.truncateValue(h.valueString(v)))
+	}
+}
+
+// truncateValue truncates s to MaxValueLen runes, if set. The number
+// of bytes omitted is appended to truncated values.
+func (h *CLIHandler) truncateValue(s string) string {
+	limit := h.opts.MaxValueLen
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+	i := 0
+	for n := 0; n < limit && i < len(s); n++ {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	if i == len(s) {
+		return s
+	}
+	return s[:i] + ellipsis + "(+" + strconv.Itoa(len(s)-i) + " bytes)"
+}
+
+// appendTimeString appends to dst the time t formatted like
+// [time.Time.String] without the monotonic clock reading.
+func appendTimeString(dst []byte, t time.Time) []byte {
+	return t.AppendFormat(dst, "2006-01-02 15:04:05.999999999 -0700 MST")
+}
+
+// appendString appends to b the string s, quoted according to the
+// configured quoting mode.
+func (h *CLIHandler) appendString(b *buffer, s string) {
+	switch h.opts.Quote {
+	case QuoteAlways:
+		*b = strconv.AppendQuote(*b, s)
+	case QuoteNever:
+		h.appendEscaped(b, s)
+	default:
+		if needsQuoting(s) {
+			*b = strconv.AppendQuote(*b, s)
+		} else {
+			h.appendEscaped(b, s)
+		}
+	}
+}
+
+// appendBlock appends to b an indented block with the key and the
+// lines of the provided value. The block is indented according to
+// depth.
+func (h *CLIHandler) appendBlock(b *buffer, depth int, key, val string) {
+	appendIndent(b, depth+1)
+	b.WriteString(key + ":\n")
+	val = strings.TrimSuffix(val, "\n")
+	for _, l := range strings.Split(h.escapeBlock(val), "\n") {
+		appendIndent(b, depth+2)
+		b.WriteString(l + "\n")
+	}
+}
+
+// appendIndent appends to b the indentation of the provided depth.
+func appendIndent(b *buffer, depth int) {
+	for i := 0; i < depth; i++ {
+		b.WriteString("  ")
+	}
+}
+
+// escapeBlock escapes the control characters in the multi-line string
+// s, except newlines. Tabs are expanded, so indentation is preserved.
+func (h *CLIHandler) escapeBlock(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = h.escape(strings.ReplaceAll(l, "\t", "    "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escape escapes the control characters in s, unless NoEscape is
+// set.
+func (h *CLIHandler) escape(s string) string {
+	if h.opts.NoEscape {
+		return s
+	}
+	return escape(s)
+}
+
+// appendEscaped appends s to b, escaping its control characters
+// unless NoEscape is set.
+func (h *CLIHandler) appendEscaped(b *buffer, s string) {
+	b.WriteString(h.escape(s))
+}
+
+// builtin passes the built-in attribute with the provided key and
+// value to ReplaceAttr, if any, and returns the resulting value. It
+// returns false if the attribute must be discarded.
+func (h *CLIHandler) builtin(key string, v slog.Value) (slog.Value, bool) {
+	if h.opts.ReplaceAttr == nil {
+		return v, true
+	}
+	a := h.opts.ReplaceAttr(nil, slog.Attr{Key: key, Value: v})
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return slog.Value{}, false
+	}
+	return a.Value, true
+}
+
+// levelLabel returns the label of the provided level after applying
+// ReplaceAttr. It returns false if the level must be omitted.
+func (h *CLIHandler) levelLabel(level slog.Level) (string, bool) {
+	if h.opts.ReplaceAttr == nil {
+		// Avoid boxing the level when there is nothing to
+		// replace.
+		return h.levelName(level), true
+	}
+	v, ok := h.builtin(slog.LevelKey, slog.AnyValue(level))
+	if !ok {
+		return "", false
+	}
+	return h.levelString(v), true
+}
+
+// now returns the current time according to the configured clock.
+func (h *CLIHandler) now() time.Time {
+	if h.opts.Now != nil {
+		return h.opts.Now()
+	}
+	return time.Now()
+}
+
+// appendTimeValue appends to b the string representation of the time
+// value v according to the configured time format.
+func (h *CLIHandler) appendTimeValue(b *buffer, v slog.Value) {
+	if v.Kind() != slog.KindTime {
+		b.WriteString(v.String())
+		return
+	}
+	t := v.Time()
+	if h.opts.TimeZone != nil {
+		t = t.In(h.opts.TimeZone)
+	}
+	switch h.opts.TimeFormat {
+	case TimeUnix:
+		*b = strconv.AppendInt(*b, t.Unix(), 10)
+	case TimeUnixMilli:
+		*b = strconv.AppendInt(*b, t.UnixMilli(), 10)
+	case TimeUnixMicro:
+		*b = strconv.AppendInt(*b, t.UnixMicro(), 10)
+	case TimeUnixNano:
+		*b = strconv.AppendInt(*b, t.UnixNano(), 10)
+	case TimeElapsed:
+		d := t.Sub(h.start)
+		if d >= 0 {
+			b.WriteByte('+')
+		}
+		prec := h.opts.TimePrecision.digits()
+		if prec < 0 {
+			prec = 3
+		}
+		*b = strconv.AppendFloat(*b, d.Seconds(), 'f', prec, 64)
+		b.WriteByte('s')
+	default:
+		*b = t.AppendFormat(*b, h.opts.TimeFormat)
 	}
 }
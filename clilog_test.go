@@ -3,6 +3,7 @@ package clilog
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -19,6 +20,8 @@ func TestCLIHandler(t *testing.T) {
 		name  string
 		opts  *HandlerOptions
 		with  func(*slog.Logger) *slog.Logger
+		level slog.Level
+		msg   string
 		attrs []slog.Attr
 		want  string
 	}{
@@ -58,6 +61,572 @@ func TestCLIHandler(t *testing.T) {
 			attrs: []slog.Attr{slog.String("c", "foo"), slog.Bool("b", true)},
 			want:  `2023-09-20T12:24:43Z INFO message wa=1 wb=2 p1.wc=3 p1.p2.c=foo p1.p2.b=true`,
 		},
+		{
+			name: "ReplaceAttr",
+			opts: &HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					switch {
+					case a.Key == "c":
+						return slog.String("renamed", a.Value.String())
+					case a.Key == "b":
+						return slog.Attr{}
+					case len(groups) == 1 && groups[0] == "g":
+						return slog.Int(a.Key, 10*int(a.Value.Int64()))
+					}
+					return a
+				},
+			},
+			attrs: []slog.Attr{
+				slog.String("c", "foo"),
+				slog.Group("g", slog.Int("a", 1), slog.Int("d", 4)),
+				slog.Bool("b", true),
+			},
+			want: `2023-09-20T12:24:43Z INFO message renamed=foo g.a=10 g.d=40`,
+		},
+		{
+			name: "ReplaceAttr,WithGroup",
+			opts: &HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if groups == nil {
+						return a
+					}
+					return slog.String(a.Key, strings.Join(groups, "/"))
+				},
+			},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.WithGroup("p1").With("wa", 1).WithGroup("p2")
+			},
+			attrs: []slog.Attr{slog.Group("g", slog.Int("a", 1))},
+			want:  `2023-09-20T12:24:43Z INFO message p1.wa=p1 p1.p2.g.a=p1/p2/g`,
+		},
+		{
+			name: "ReplaceAttr builtin",
+			opts: &HandlerOptions{
+				AddSource: true,
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					switch a.Key {
+					case slog.TimeKey:
+						return slog.Attr{}
+					case slog.LevelKey:
+						return slog.String(a.Key, "LVL")
+					case slog.SourceKey:
+						src := a.Value.Any().(*slog.Source)
+						src.File = "file.go"
+					case slog.MessageKey:
+						return slog.String(a.Key, "replaced")
+					}
+					return a
+				},
+			},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `LVL file.go:$LINE replaced c=foo`,
+		},
+		{
+			name:  "LogValuer",
+			attrs: []slog.Attr{slog.Any("v", testValuer("foo"))},
+			want:  `2023-09-20T12:24:43Z INFO message v="valued foo"`,
+		},
+		{
+			name:  "Color",
+			opts:  &HandlerOptions{Color: ColorAlways},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  "\x1b[2m2023-09-20T12:24:43Z\x1b[0m \x1b[34mINFO\x1b[0m message c=foo",
+		},
+		{
+			name:  "Color auto",
+			opts:  &HandlerOptions{Color: ColorAuto},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z INFO message c=foo`,
+		},
+		{
+			name: "Color,Theme",
+			opts: &HandlerOptions{
+				Color: ColorAlways,
+				Theme: &Theme{Info: "\x1b[1m"},
+			},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  "2023-09-20T12:24:43Z \x1b[1mINFO\x1b[0m message c=foo",
+		},
+		{
+			name:  "TimeFormat",
+			opts:  &HandlerOptions{TimeFormat: "15:04:05.000"},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `12:24:43.000 INFO message c=foo`,
+		},
+		{
+			name:  "TimeFormat unix",
+			opts:  &HandlerOptions{TimeFormat: TimeUnix},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `1695212683 INFO message c=foo`,
+		},
+		{
+			name:  "TimeFormat unixmilli",
+			opts:  &HandlerOptions{TimeFormat: TimeUnixMilli},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `1695212683000 INFO message c=foo`,
+		},
+		{
+			name:  "TimeZone",
+			opts:  &HandlerOptions{TimeFormat: time.TimeOnly, TimeZone: time.FixedZone("CEST", 2*60*60)},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `14:24:43 INFO message c=foo`,
+		},
+		{
+			name:  "OmitTime",
+			opts:  &HandlerOptions{OmitTime: true, AddSource: true},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO $SOURCE message c=foo`,
+		},
+		{
+			name:  "Quote",
+			attrs: []slog.Attr{slog.String("c", "hello world"), slog.String("e", ""), slog.Int("n", 1)},
+			want:  `2023-09-20T12:24:43Z INFO message c="hello world" e="" n=1`,
+		},
+		{
+			name:  "Quote always",
+			opts:  &HandlerOptions{Quote: QuoteAlways},
+			attrs: []slog.Attr{slog.String("c", "foo"), slog.Int("n", 1)},
+			want:  `2023-09-20T12:24:43Z INFO message c="foo" n="1"`,
+		},
+		{
+			name:  "Quote never",
+			opts:  &HandlerOptions{Quote: QuoteNever},
+			attrs: []slog.Attr{slog.String("c", "hello world")},
+			want:  `2023-09-20T12:24:43Z INFO message c=hello world`,
+		},
+		{
+			name: "escape",
+			opts: &HandlerOptions{Quote: QuoteNever},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.WithGroup("g\n")
+			},
+			attrs: []slog.Attr{slog.String("c\r", "foo\nbar\x1b[2J")},
+			want:  `2023-09-20T12:24:43Z INFO message g\n.c\r=foo\nbar\x1b[2J`,
+		},
+		{
+			name: "escape message",
+			msg:  "foo\n2023-09-20T12:24:43Z ERROR forged",
+			want: `2023-09-20T12:24:43Z INFO foo\n2023-09-20T12:24:43Z ERROR forged`,
+		},
+		{
+			name:  "NoEscape",
+			opts:  &HandlerOptions{Quote: QuoteNever, NoEscape: true},
+			attrs: []slog.Attr{slog.String("c", "foo\tbar")},
+			want:  "2023-09-20T12:24:43Z INFO message c=foo\tbar",
+		},
+		{
+			name:  "MessageFirst",
+			opts:  &HandlerOptions{Layout: MessageFirst, TimeFormat: time.TimeOnly},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO message  (12:24:43) c=foo`,
+		},
+		{
+			name:  "MessageFirst,AddSource",
+			opts:  &HandlerOptions{Layout: MessageFirst, TimeFormat: time.TimeOnly, AddSource: true},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO message  (12:24:43 $SOURCE) c=foo`,
+		},
+		{
+			name:  "MessageFirst,OmitTime,AddSource",
+			opts:  &HandlerOptions{Layout: MessageFirst, OmitTime: true, AddSource: true},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO message  ($SOURCE) c=foo`,
+		},
+		{
+			name:  "MessageFirst,OmitTime",
+			opts:  &HandlerOptions{Layout: MessageFirst, OmitTime: true},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO message c=foo`,
+		},
+		{
+			name:  "Template",
+			opts:  &HandlerOptions{Template: "{level} {time} {source} {msg}{attrs}", AddSource: true},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO 2023-09-20T12:24:43Z $SOURCE message c=foo`,
+		},
+		{
+			name: "Template,OmitTime",
+			opts: &HandlerOptions{Template: "{level} {time} {source} {msg}{attrs}", OmitTime: true},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("w", "bar")
+			},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `INFO message w=bar c=foo`,
+		},
+		{
+			name:  "Template without attrs",
+			opts:  &HandlerOptions{Template: "{msg} [{level}]"},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `message [INFO]`,
+		},
+		{
+			name:  "GroupsBracketed",
+			opts:  &HandlerOptions{OmitTime: true, GroupStyle: GroupsBracketed},
+			attrs: []slog.Attr{slog.String("c", "foo"), slog.Group("g", slog.Int("a", 1), slog.Group("h", slog.Int("b", 2)), slog.Int("d", 4)), slog.Bool("b", true)},
+			want:  `INFO message c=foo g=[a=1 h=[b=2] d=4] b=true`,
+		},
+		{
+			name: "GroupsBracketed,WithGroup",
+			opts: &HandlerOptions{OmitTime: true, GroupStyle: GroupsBracketed},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("w", 1).WithGroup("g").With("a", 1).WithGroup("h")
+			},
+			attrs: []slog.Attr{slog.Int("b", 2), slog.Group("i", slog.Int("c", 3))},
+			want:  `INFO message w=1 g=[a=1 h=[b=2 i=[c=3]]]`,
+		},
+		{
+			name: "GroupsBracketed,WithGroup without attrs",
+			opts: &HandlerOptions{OmitTime: true, GroupStyle: GroupsBracketed},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.WithGroup("g").With("a", 1).WithGroup("h")
+			},
+			want: `INFO message g=[a=1]`,
+		},
+		{
+			name:  "GroupsBracketed empty group",
+			opts:  &HandlerOptions{OmitTime: true, GroupStyle: GroupsBracketed},
+			attrs: []slog.Attr{slog.Group("g", slog.Group("h")), slog.Int("a", 1)},
+			want:  `INFO message a=1`,
+		},
+		{
+			name: "Expanded",
+			opts: &HandlerOptions{OmitTime: true, Expanded: true, Multiline: true},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("w", 1).WithGroup("g").With("a", 1)
+			},
+			attrs: []slog.Attr{
+				slog.Group("h", slog.String("b", "hello world"), slog.String("out", "line 1\nline 2")),
+				slog.Int("c", 3),
+			},
+			want: "INFO message\n" +
+				"  w=1\n" +
+				"  g:\n" +
+				"    a=1\n" +
+				"    h:\n" +
+				"      b=\"hello world\"\n" +
+				"      out:\n" +
+				"        line 1\n" +
+				"        line 2\n" +
+				"    c=3",
+		},
+		{
+			name: "KeyOrder",
+			opts: &HandlerOptions{OmitTime: true, KeyOrder: KeyOrder{First: []string{"id"}, Last: []string{"err"}, Sort: true}},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("z", 1, "id", 2)
+			},
+			attrs: []slog.Attr{slog.String("err", "fail"), slog.Group("g", slog.Int("b", 1), slog.Int("a", 2)), slog.String("c", "foo")},
+			want:  `INFO message id=2 z=1 c=foo g.a=2 g.b=1 err=fail`,
+		},
+		{
+			name: "DropKeys",
+			opts: &HandlerOptions{OmitTime: true, DropKeys: []string{"trace_id", "http.headers.*", "debug"}},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("trace_id", "abc").WithGroup("http")
+			},
+			attrs: []slog.Attr{
+				slog.String("method", "GET"),
+				slog.Group("headers", slog.String("accept", "*/*")),
+				slog.Group("debug", slog.Int("a", 1)),
+			},
+			want: `INFO message http.method=GET`,
+		},
+		{
+			name: "OnlyKeys",
+			opts: &HandlerOptions{OmitTime: true, OnlyKeys: []string{"id", "http", "db.query"}},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("id", 1, "trace_id", "abc")
+			},
+			attrs: []slog.Attr{
+				slog.Group("http", slog.String("method", "GET"), slog.Group("headers", slog.String("accept", "*/*"))),
+				slog.Group("db", slog.String("query", "SELECT"), slog.Int("rows", 3)),
+				slog.Group("other", slog.Int("a", 1)),
+			},
+			want: `INFO message id=1 http.method=GET http.headers.accept=*/* db.query=SELECT`,
+		},
+		{
+			name: "RenameKeys",
+			opts: &HandlerOptions{OmitTime: true, RenameKeys: map[string]string{"http.request.method": "method", "status_code": "status", "trace_id": "trace"}},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("trace_id", "abc").WithGroup("http")
+			},
+			attrs: []slog.Attr{
+				slog.Group("request", slog.String("method", "GET")),
+				slog.Group("response", slog.Int("status_code", 200)),
+			},
+			want: `INFO message trace=abc method=GET http.response.status=200`,
+		},
+		{
+			name: "StripPrefixes",
+			opts: &HandlerOptions{OmitTime: true, StripPrefixes: []string{"http.request", "http"}},
+			attrs: []slog.Attr{
+				slog.Group("http", slog.Group("request", slog.String("method", "GET")), slog.Int("status", 200)),
+				slog.Group("httpx", slog.Int("a", 1)),
+				slog.Group("db", slog.Group("http", slog.Int("b", 2))),
+			},
+			want: `INFO message method=GET status=200 httpx.a=1 db.http.b=2`,
+		},
+		{
+			name:  "MaxValueLen",
+			opts:  &HandlerOptions{OmitTime: true, MaxValueLen: 5, Quote: QuoteNever},
+			attrs: []slog.Attr{slog.String("a", "ñandú ñandú"), slog.String("b", "short"), slog.Int("n", 1234567), slog.Any("s", []int{1, 2, 3})},
+			want:  `INFO message a=ñandú…(+8 bytes) b=short n=1234567 s=[1,2,…(+2 bytes)`,
+		},
+		{
+			name:  "MaxValueLen,Multiline",
+			opts:  &HandlerOptions{OmitTime: true, MaxValueLen: 8, Multiline: true},
+			attrs: []slog.Attr{slog.String("out", "line 1\nline 2\nline 3")},
+			want:  "INFO message\n  out:\n    line 1\n    l…(+12 bytes)",
+		},
+		{
+			name:  "KVSeparator",
+			opts:  &HandlerOptions{OmitTime: true, KVSeparator: ": "},
+			attrs: []slog.Attr{slog.String("c", "foo"), slog.Int("n", 1)},
+			want:  `INFO message c: foo n: 1`,
+		},
+		{
+			name: "AttrSeparator",
+			opts: &HandlerOptions{OmitTime: true, AttrSeparator: "\t"},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("w", "bar")
+			},
+			attrs: []slog.Attr{slog.String("c", "foo"), slog.Int("n", 1)},
+			want:  "INFO message\tw=bar\tc=foo\tn=1",
+		},
+		{
+			name: "Multiline",
+			opts: &HandlerOptions{Multiline: true},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("out", "line 1\nline 2\n").WithGroup("g")
+			},
+			attrs: []slog.Attr{
+				slog.String("c", "foo"),
+				slog.String("stack", "main.main()\n\tmain.go:1\x1b[2J"),
+			},
+			want: "2023-09-20T12:24:43Z INFO message g.c=foo\n" +
+				"  out:\n" +
+				"    line 1\n" +
+				"    line 2\n" +
+				"  g.stack:\n" +
+				"    main.main()\n" +
+				"        main.go:1\\x1b[2J",
+		},
+		{
+			name:  "Multiline disabled",
+			attrs: []slog.Attr{slog.String("out", "line 1\nline 2")},
+			want:  `2023-09-20T12:24:43Z INFO message out="line 1\nline 2"`,
+		},
+		{
+			name: "LevelNames",
+			opts: &HandlerOptions{
+				LevelNames: map[slog.Leveler]string{
+					slog.LevelInfo: "INF",
+					slog.LevelWarn: "WRN",
+				},
+			},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z INF message c=foo`,
+		},
+		{
+			name: "LevelNames warn",
+			opts: &HandlerOptions{
+				LevelNames: map[slog.Leveler]string{
+					slog.LevelInfo: "INF",
+					slog.LevelWarn: "WRN",
+				},
+			},
+			level: slog.LevelWarn,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z WRN message c=foo`,
+		},
+		{
+			name:  "custom level",
+			opts:  &HandlerOptions{Level: LevelTrace},
+			level: LevelTrace,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z TRACE message c=foo`,
+		},
+		{
+			name: "custom level renamed",
+			opts: &HandlerOptions{
+				LevelNames: map[slog.Leveler]string{LevelFatal: "FTL"},
+			},
+			level: LevelFatal,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z FTL message c=foo`,
+		},
+		{
+			name: "LevelNames missing",
+			opts: &HandlerOptions{
+				LevelNames: map[slog.Leveler]string{slog.LevelInfo: "INF"},
+			},
+			level: slog.LevelError + 2,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z ERROR+2 message c=foo`,
+		},
+		{
+			name:  "LevelWidth",
+			opts:  &HandlerOptions{LevelWidth: 5, Color: ColorAlways, Theme: &Theme{Info: "\x1b[1m"}},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  "2023-09-20T12:24:43Z \x1b[1mINFO\x1b[0m  message c=foo",
+		},
+		{
+			name:  "MessageWidth",
+			opts:  &HandlerOptions{OmitTime: true, LevelWidth: 5, MessageWidth: 10},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  "INFO  message    c=foo",
+		},
+		{
+			name: "MessageWidth,WithAttrs",
+			opts: &HandlerOptions{OmitTime: true, MessageWidth: 10},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("w", "bar")
+			},
+			want: "INFO message    w=bar",
+		},
+		{
+			name: "MessageWidth without attrs",
+			opts: &HandlerOptions{OmitTime: true, MessageWidth: 10},
+			want: "INFO message",
+		},
+		{
+			name: "MessageWidth long message",
+			opts: &HandlerOptions{OmitTime: true, MessageWidth: 4},
+			want: "INFO message",
+		},
+		{
+			name:  "MessageWidth,MessageFirst",
+			opts:  &HandlerOptions{TimeFormat: time.TimeOnly, Layout: MessageFirst, MessageWidth: 10},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  "INFO message     (12:24:43) c=foo",
+		},
+		{
+			name:  "MessageWidth,Template",
+			opts:  &HandlerOptions{Template: "{msg} {level}{attrs}", MessageWidth: 10},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  "message    INFO c=foo",
+		},
+		{
+			name:  "Icons prefix",
+			opts:  &HandlerOptions{Icons: IconsPrefix},
+			level: slog.LevelWarn,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z ! WARN message c=foo`,
+		},
+		{
+			name:  "Icons replace",
+			opts:  &HandlerOptions{Icons: IconsReplace, OmitTime: true},
+			level: slog.LevelError,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `✖ message c=foo`,
+		},
+		{
+			name: "Icons replace,IconSet,Color",
+			opts: &HandlerOptions{
+				Icons:   IconsReplace,
+				IconSet: &IconSet{Info: "i"},
+				Color:   ColorAlways,
+				Theme:   &Theme{Info: "\x1b[1m"},
+			},
+			level: LevelNotice,
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  "2023-09-20T12:24:43Z \x1b[1mi\x1b[0m message c=foo",
+		},
+		{
+			name:  "SourceFormat",
+			opts:  &HandlerOptions{AddSource: true, SourceFormat: SourceShort},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z INFO clilog_test.go:$LINE message c=foo`,
+		},
+		{
+			name: "WithGroup empty",
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.WithGroup("").With("wa", 1).WithGroup("g").WithGroup("")
+			},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `2023-09-20T12:24:43Z INFO message wa=1 g.c=foo`,
+		},
+		{
+			name: "WithGroup no attrs",
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("wa", 1).WithGroup("g")
+			},
+			want: `2023-09-20T12:24:43Z INFO message wa=1`,
+		},
+		{
+			name: "empty group",
+			attrs: []slog.Attr{
+				slog.Group("g"),
+				slog.Group("h", slog.Group("i")),
+				slog.Group("", slog.String("c", "foo")),
+				{},
+			},
+			want: `2023-09-20T12:24:43Z INFO message c=foo`,
+		},
+		{
+			name: "RedactKeys",
+			opts: &HandlerOptions{RedactKeys: []string{"password", "*_token", "db.http.headers"}},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("api_token", "secret").WithGroup("db")
+			},
+			attrs: []slog.Attr{
+				slog.String("user", "foo"),
+				slog.String("Password", "secret"),
+				slog.Group("http",
+					slog.String("method", "GET"),
+					slog.Group("headers", slog.String("cookie", "secret")),
+				),
+			},
+			want: `2023-09-20T12:24:43Z INFO message api_token=[REDACTED] db.user=foo db.Password=[REDACTED] db.http.method=GET db.http.headers=[REDACTED]`,
+		},
+		{
+			name:  "Secret",
+			attrs: []slog.Attr{slog.Any("token", Secret("abcdef"))},
+			want:  `2023-09-20T12:24:43Z INFO message token=***`,
+		},
+		{
+			name:  "RevealSecrets",
+			opts:  &HandlerOptions{RevealSecrets: 2},
+			attrs: []slog.Attr{slog.Any("token", Secret("abcdef"))},
+			want:  `2023-09-20T12:24:43Z INFO message token=***ef`,
+		},
+		{
+			name:  "error",
+			opts:  &HandlerOptions{Color: ColorAlways, Theme: &Theme{ErrorAttr: "\x1b[31m"}},
+			attrs: []slog.Attr{slog.Any("err", errors.New("failure")), slog.String("c", "foo")},
+			want:  "2023-09-20T12:24:43Z INFO message \x1b[31merr=failure\x1b[0m c=foo",
+		},
+		{
+			name: "error,WithAttrs",
+			opts: &HandlerOptions{Color: ColorAlways, Theme: &Theme{ErrorAttr: "\x1b[31m"}},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("err", errors.New("failure"))
+			},
+			want: "2023-09-20T12:24:43Z INFO message \x1b[31merr=failure\x1b[0m",
+		},
+		{
+			name:  "DimKeys",
+			opts:  &HandlerOptions{Color: ColorAlways, Theme: &Theme{Dim: "\x1b[2m"}, DimKeys: []string{"trace_id"}},
+			attrs: []slog.Attr{slog.String("trace_id", "abc"), slog.Group("g", slog.String("trace_id", "def")), slog.String("c", "foo")},
+			want:  "2023-09-20T12:24:43Z INFO message \x1b[2mtrace_id=abc\x1b[0m \x1b[2mg.trace_id=def\x1b[0m c=foo",
+		},
+		{
+			name: "ErrorChain",
+			opts: &HandlerOptions{ErrorChain: true},
+			attrs: []slog.Attr{
+				slog.Any("err", fmt.Errorf("read config: %w", fmt.Errorf("open file: %w", errors.New("permission denied")))),
+				slog.Any("join", errors.Join(errors.New("a"), fmt.Errorf("b: %w", errors.New("c")))),
+				slog.Any("plain", errors.New("plain")),
+			},
+			want: `2023-09-20T12:24:43Z INFO message err="read config: open file: permission denied" join="a\nb: c" plain=plain` + "\n" +
+				"  err:\n" +
+				"    caused by: open file: permission denied\n" +
+				"      caused by: permission denied\n" +
+				"  join:\n" +
+				"    caused by: a\n" +
+				"    caused by: b: c\n" +
+				"      caused by: c",
+		},
 	}
 
 	for _, tt := range tests {
@@ -71,7 +640,11 @@ func TestCLIHandler(t *testing.T) {
 				logger = tt.with(logger)
 			}
 
-			logger.LogAttrs(context.Background(), slog.LevelInfo, "message", tt.attrs...)
+			msg := "message"
+			if tt.msg != "" {
+				msg = tt.msg
+			}
+			logger.LogAttrs(context.Background(), tt.level, msg, tt.attrs...)
 			_, file, line, ok := runtime.Caller(0)
 			if !ok {
 				t.Fatalf("could not get source line")
@@ -82,6 +655,7 @@ func TestCLIHandler(t *testing.T) {
 			source := fmt.Sprintf("%v:%v", file, line-1)
 
 			want := strings.ReplaceAll(tt.want, "$SOURCE", source)
+			want = strings.ReplaceAll(want, "$LINE", fmt.Sprint(line-1))
 			if got := strings.TrimSuffix(buf.String(), "\n"); got != want {
 				t.Errorf("unexpected log line:\ngot  %s\nwant %s", got, want)
 			}
@@ -132,6 +706,176 @@ func TestCLIHandler_Enabled(t *testing.T) {
 	}
 }
 
+func TestCLIHandler_elapsed(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    string
+	}{
+		{
+			name:    "zero",
+			elapsed: 0,
+			want:    "+0.000s INFO message",
+		},
+		{
+			name:    "millis",
+			elapsed: 3 * time.Millisecond,
+			want:    "+0.003s INFO message",
+		},
+		{
+			name:    "seconds",
+			elapsed: 12*time.Second + 450*time.Millisecond,
+			want:    "+12.450s INFO message",
+		},
+		{
+			name:    "negative",
+			elapsed: -2 * time.Second,
+			want:    "-2.000s INFO message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			h := NewCLIHandler(&buf, &HandlerOptions{TimeFormat: TimeElapsed})
+			logger := slog.New(setTimeHandler{h.start.Add(tt.elapsed), h})
+			logger.Info("message")
+
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.want {
+				t.Errorf("unexpected log line:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Now(t *testing.T) {
+	var buf bytes.Buffer
+
+	now := testTime
+	h := NewCLIHandler(&buf, &HandlerOptions{
+		TimeFormat: time.TimeOnly,
+		Now:        func() time.Time { return now },
+	})
+	logger := slog.New(h)
+	logger.Info("first")
+	now = now.Add(90 * time.Second)
+	logger.Info("second")
+
+	elapsed := slog.New(NewCLIHandler(&buf, &HandlerOptions{
+		TimeFormat: TimeElapsed,
+		Now:        func() time.Time { return now },
+	}))
+	now = now.Add(1500 * time.Millisecond)
+	elapsed.Info("third")
+
+	want := "12:24:43 INFO first\n12:26:13 INFO second\n+1.500s INFO third\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestCLIHandler_StampZero(t *testing.T) {
+	var buf bytes.Buffer
+
+	ctx := context.Background()
+	now := func() time.Time { return testTime }
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "message", 0)
+	NewCLIHandler(&buf, &HandlerOptions{TimeFormat: time.TimeOnly, Now: now}).Handle(ctx, r)
+	NewCLIHandler(&buf, &HandlerOptions{TimeFormat: time.TimeOnly, Now: now, StampZero: true}).Handle(ctx, r)
+
+	want := "INFO message\n12:24:43 INFO message\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestCLIHandler_UseUTC(t *testing.T) {
+	var buf bytes.Buffer
+
+	now := testTime.In(time.FixedZone("PDT", -7*60*60))
+	for _, opts := range []*HandlerOptions{
+		{TimeFormat: time.TimeOnly},
+		{TimeFormat: time.TimeOnly, UseUTC: true},
+		{TimeFormat: time.TimeOnly, UseUTC: true, TimeZone: time.FixedZone("CEST", 2*60*60)},
+	} {
+		opts.Now = func() time.Time { return now }
+		slog.New(NewCLIHandler(&buf, opts)).Info("message")
+	}
+
+	want := "05:24:43 INFO message\n12:24:43 INFO message\n14:24:43 INFO message\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+// countWriter is a writer that counts the calls to Write.
+type countWriter struct {
+	bytes.Buffer
+	n int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n++
+	return w.Buffer.Write(p)
+}
+
+func TestCLIHandler_singleWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  HandlerOptions
+		attrs []slog.Attr
+	}{
+		{
+			name:  "stack trace",
+			opts:  HandlerOptions{},
+			attrs: []slog.Attr{Stack()},
+		},
+		{
+			name:  "multiline",
+			opts:  HandlerOptions{Multiline: true},
+			attrs: []slog.Attr{slog.String("out", "line 1\nline 2")},
+		},
+		{
+			name:  "expanded",
+			opts:  HandlerOptions{Expanded: true},
+			attrs: []slog.Attr{slog.Group("g", slog.Int("a", 1), slog.Int("b", 2))},
+		},
+		{
+			name:  "wrap",
+			opts:  HandlerOptions{Overflow: OverflowWrap, Width: 20},
+			attrs: []slog.Attr{slog.String("a", "aaaaaaaaaa"), slog.String("b", "bbbbbbbbbb")},
+		},
+		{
+			name:  "error chain",
+			opts:  HandlerOptions{ErrorChain: true},
+			attrs: []slog.Attr{slog.Any("err", fmt.Errorf("outer: %w", errors.New("inner")))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w countWriter
+
+			tt.opts.OmitTime = true
+			slog.New(NewCLIHandler(&w, &tt.opts)).LogAttrs(context.Background(), slog.LevelInfo, "message", tt.attrs...)
+
+			if lines := strings.Count(w.String(), "\n"); lines < 2 {
+				t.Errorf("expected a multi-line record: %q", w.String())
+			}
+			if w.n != 1 {
+				t.Errorf("unexpected number of writes: got: %v, want: 1", w.n)
+			}
+		})
+	}
+}
+
+type testValuer string
+
+func (v testValuer) LogValue() slog.Value {
+	return slog.StringValue("valued " + string(v))
+}
+
 type setTimeHandler struct {
 	t time.Time
 	h slog.Handler
@@ -153,3 +897,159 @@ func (h setTimeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 func (h setTimeHandler) WithGroup(name string) slog.Handler {
 	return setTimeHandler{t: h.t, h: h.h.WithGroup(name)}
 }
+
+func TestCLIHandler_Handle_allocs(t *testing.T) {
+	h := NewCLIHandler(io.Discard, &HandlerOptions{Level: slog.LevelDebug}).
+		WithAttrs([]slog.Attr{slog.String("with", "value")})
+
+	ctx := context.Background()
+	r := slog.NewRecord(testTime, slog.LevelDebug, "message", 0)
+	r.AddAttrs(
+		slog.String("string", "value"),
+		slog.Int("int", 42),
+		slog.Bool("bool", true),
+		slog.Float64("float", 1.5),
+		slog.Time("time", testTime),
+		slog.Duration("duration", 1500*time.Millisecond),
+		slog.String("quoted", "quoted value"),
+	)
+	allocs := testing.AllocsPerRun(100, func() {
+		h.Handle(ctx, r)
+	})
+	if allocs != 0 {
+		t.Errorf("unexpected allocations: %v", allocs)
+	}
+}
+
+func TestCLIHandler_WithAttrs_shared(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}).
+		WithAttrs([]slog.Attr{slog.String("a", "1")})
+	h1 := h.WithAttrs([]slog.Attr{slog.String("b", "2")})
+	h2 := h.WithAttrs([]slog.Attr{slog.String("c", "3")})
+
+	ctx := context.Background()
+	for _, h := range []slog.Handler{h, h1, h2} {
+		r := slog.NewRecord(time.Time{}, slog.LevelInfo, "message", 0)
+		if err := h.Handle(ctx, r); err != nil {
+			t.Fatalf("handle error: %v", err)
+		}
+	}
+
+	want := "INFO message a=1\n" +
+		"INFO message a=1 b=2\n" +
+		"INFO message a=1 c=3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func BenchmarkCLIHandler(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		new  func(io.Writer) slog.Handler
+	}{
+		{
+			name: "CLIHandler",
+			new: func(w io.Writer) slog.Handler {
+				return NewCLIHandler(w, nil)
+			},
+		},
+		{
+			name: "TextHandler",
+			new: func(w io.Writer) slog.Handler {
+				return slog.NewTextHandler(w, nil)
+			},
+		},
+	}
+
+	attrs := []slog.Attr{
+		slog.String("string", "value"),
+		slog.Int("int", 42),
+		slog.Bool("bool", true),
+		slog.Float64("float", 1.5),
+		slog.Time("time", testTime),
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			h := bm.new(io.Discard)
+			ctx := context.Background()
+			r := slog.NewRecord(testTime, slog.LevelInfo, "message", 0)
+			r.AddAttrs(attrs...)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.Handle(ctx, r)
+			}
+		})
+	}
+}
+
+func BenchmarkCLIHandler_WithAttrs(b *testing.B) {
+	attrs := []slog.Attr{
+		slog.String("command", "build"),
+		slog.String("target", "./..."),
+		slog.Int("jobs", 8),
+	}
+
+	b.Run("preformatted", func(b *testing.B) {
+		h := NewCLIHandler(io.Discard, nil).WithAttrs(attrs)
+		ctx := context.Background()
+		r := slog.NewRecord(testTime, slog.LevelInfo, "message", 0)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Handle(ctx, r)
+		}
+	})
+
+	b.Run("record", func(b *testing.B) {
+		h := NewCLIHandler(io.Discard, nil)
+		ctx := context.Background()
+		r := slog.NewRecord(testTime, slog.LevelInfo, "message", 0)
+		r.AddAttrs(attrs...)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Handle(ctx, r)
+		}
+	})
+}
+
+func BenchmarkCLIHandler_kinds(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		attr slog.Attr
+	}{
+		{"String", slog.String("key", "value")},
+		{"QuotedString", slog.String("key", "quoted value")},
+		{"Int64", slog.Int64("key", -42)},
+		{"Uint64", slog.Uint64("key", 42)},
+		{"Float64", slog.Float64("key", 3.14)},
+		{"Bool", slog.Bool("key", true)},
+		{"Duration", slog.Duration("key", 1500*time.Millisecond)},
+		{"Time", slog.Time("key", testTime)},
+		{"Any", slog.Any("key", []int{1, 2, 3})},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			h := NewCLIHandler(io.Discard, nil)
+			ctx := context.Background()
+			r := slog.NewRecord(testTime, slog.LevelInfo, "message", 0)
+			for i := 0; i < 10; i++ {
+				r.AddAttrs(bm.attr)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.Handle(ctx, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package cliloghttp
+
+import (
+	"expvar"
+
+	"github.com/jroimartin/clilog"
+)
+
+// Metrics returns the metrics of the log volume of h and the handlers
+// derived from it: the number of records per level ("records") and
+// the number of write errors ("write_errors").
+func Metrics(h *clilog.CLIHandler) map[string]any {
+	s := h.Stats()
+	return map[string]any{
+		"records": map[string]uint64{
+			"trace":  s.Trace,
+			"debug":  s.Debug,
+			"info":   s.Info,
+			"notice": s.Notice,
+			"warn":   s.Warn,
+			"error":  s.Error,
+			"fatal":  s.Fatal,
+		},
+		"write_errors": h.Errors(),
+	}
+}
+
+// PublishExpvar publishes the metrics returned by [Metrics] as the
+// [expvar] variable with the provided name, so they are served by
+// the "/debug/vars" endpoint along with the rest of variables. Like
+// [expvar.Publish], it panics if the name is already registered.
+func PublishExpvar(name string, h *clilog.CLIHandler) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return Metrics(h)
+	}))
+}
@@ -0,0 +1,49 @@
+package cliloghttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log/slog"
+	"testing"
+
+	"github.com/jroimartin/clilog"
+)
+
+// failWriter is an [io.Writer] that always fails.
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestPublishExpvar(t *testing.T) {
+	h := clilog.NewCLIHandler(&bytes.Buffer{}, nil)
+	PublishExpvar("clilog_test", h)
+
+	logger := slog.New(h)
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	h.SetOutput(failWriter{})
+	logger.Error("error")
+
+	var got struct {
+		Records     map[string]uint64 `json:"records"`
+		WriteErrors uint64            `json:"write_errors"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("clilog_test").String()), &got); err != nil {
+		t.Fatalf("could not decode variable: %v", err)
+	}
+
+	want := map[string]uint64{"trace": 0, "debug": 0, "info": 1, "notice": 0, "warn": 1, "error": 2, "fatal": 0}
+	for level, n := range want {
+		if got.Records[level] != n {
+			t.Errorf("unexpected number of %v records: got: %v, want: %v", level, got.Records[level], n)
+		}
+	}
+	if got.WriteErrors != 1 {
+		t.Errorf("unexpected number of write errors: %v", got.WriteErrors)
+	}
+}
@@ -0,0 +1,62 @@
+// Package cliloghttp provides HTTP handlers and expvar variables to
+// inspect and configure the logging of programs using [clilog]. It is
+// a separate package, so programs that do not use it do not depend on
+// net/http.
+package cliloghttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/jroimartin/clilog"
+)
+
+// levelPayload is the JSON representation of a level exchanged by
+// the handler returned by LevelHandler.
+type levelPayload struct {
+	Level string `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// LevelHandler returns an [http.Handler] that reports and changes the
+// level stored in lv, so programs can expose it on a debug endpoint
+// (e.g. "/loglevel").
+//
+// GET requests return the current level as a JSON object like
+// {"level":"INFO"}. PUT requests set the level from a JSON object of
+// the same shape, parsed by [clilog.ParseLevel], and return the new
+// level. Errors are returned as a JSON object like {"error":"..."}.
+func LevelHandler(lv *slog.LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelPayload(w, http.StatusBadRequest, levelPayload{Error: fmt.Sprintf("invalid request body: %v", err)})
+				return
+			}
+			level, err := clilog.ParseLevel(req.Level)
+			if err != nil {
+				writeLevelPayload(w, http.StatusBadRequest, levelPayload{Error: err.Error()})
+				return
+			}
+			lv.Set(level)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelPayload(w, http.StatusMethodNotAllowed, levelPayload{Error: "only GET and PUT are supported"})
+			return
+		}
+		writeLevelPayload(w, http.StatusOK, levelPayload{Level: clilog.LevelString(lv.Level())})
+	})
+}
+
+// writeLevelPayload writes p to w as a JSON response with the provided
+// status code.
+func writeLevelPayload(w http.ResponseWriter, code int, p levelPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(p) //nolint:errcheck
+}
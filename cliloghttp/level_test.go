@@ -0,0 +1,91 @@
+package cliloghttp
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	var lv slog.LevelVar
+
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		wantCode  int
+		wantBody  string
+		wantLevel slog.Level
+	}{
+		{
+			name:      "get",
+			method:    http.MethodGet,
+			wantCode:  http.StatusOK,
+			wantBody:  `{"level":"INFO"}`,
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name:      "put",
+			method:    http.MethodPut,
+			body:      `{"level":"debug"}`,
+			wantCode:  http.StatusOK,
+			wantBody:  `{"level":"DEBUG"}`,
+			wantLevel: slog.LevelDebug,
+		},
+		{
+			name:      "put offset",
+			method:    http.MethodPut,
+			body:      `{"level":"warn+1"}`,
+			wantCode:  http.StatusOK,
+			wantBody:  `{"level":"WARN+1"}`,
+			wantLevel: slog.LevelWarn + 1,
+		},
+		{
+			name:      "put unknown level",
+			method:    http.MethodPut,
+			body:      `{"level":"loud"}`,
+			wantCode:  http.StatusBadRequest,
+			wantBody:  `{"error":"unknown level \"loud\"`,
+			wantLevel: slog.LevelWarn + 1,
+		},
+		{
+			name:      "put invalid body",
+			method:    http.MethodPut,
+			body:      `debug`,
+			wantCode:  http.StatusBadRequest,
+			wantBody:  `{"error":"invalid request body: `,
+			wantLevel: slog.LevelWarn + 1,
+		},
+		{
+			name:      "post",
+			method:    http.MethodPost,
+			wantCode:  http.StatusMethodNotAllowed,
+			wantBody:  `{"error":"only GET and PUT are supported"}`,
+			wantLevel: slog.LevelWarn + 1,
+		},
+	}
+
+	h := LevelHandler(&lv)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/loglevel", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("unexpected status code: got: %v, want: %v", rec.Code, tt.wantCode)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("unexpected content type: %v", got)
+			}
+			if got := rec.Body.String(); !strings.HasPrefix(got, tt.wantBody) {
+				t.Errorf("unexpected body: got: %q, want prefix: %q", got, tt.wantBody)
+			}
+			if got := lv.Level(); got != tt.wantLevel {
+				t.Errorf("unexpected level: got: %v, want: %v", got, tt.wantLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/jroimartin/clilog/clilogotel
+
+go 1.21.1
+
+require (
+	github.com/jroimartin/clilog v0.0.0
+	go.opentelemetry.io/otel/trace v1.21.0
+)
+
+require go.opentelemetry.io/otel v1.21.0 // indirect
+
+replace github.com/jroimartin/clilog => ../
@@ -0,0 +1,84 @@
+// Package clilogotel correlates the logs of a clilog handler with
+// OpenTelemetry traces. It is a separate module, so programs that do
+// not use OpenTelemetry do not depend on it.
+package clilogotel
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jroimartin/clilog"
+)
+
+// Keys of the attributes added by the functions of this package.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// shortIDLen is the length of the shortened trace and span IDs.
+const shortIDLen = 8
+
+// Options are options for [ContextAttrs] and [Configure]. A zero
+// Options consists entirely of default values.
+type Options struct {
+	// Short causes the trace and span IDs to be shortened to
+	// their first 8 hexadecimal digits, which is usually enough
+	// to find them in a tracing backend.
+	Short bool
+
+	// Dim causes Configure to render the trace and span IDs with
+	// the style [clilog.Theme.Dim].
+	Dim bool
+}
+
+// ContextAttrs returns a function, suitable for
+// [clilog.HandlerOptions.ContextAttrs], that returns the trace and
+// span IDs of the span stored in the context. If the context does
+// not contain a valid span, the function returns nil. If opts is nil,
+// the default options are used.
+func ContextAttrs(opts *Options) func(ctx context.Context) []slog.Attr {
+	if opts == nil {
+		opts = &Options{}
+	}
+	short := opts.Short
+	return func(ctx context.Context) []slog.Attr {
+		sc := trace.SpanContextFromContext(ctx)
+		if !sc.IsValid() {
+			return nil
+		}
+		traceID, spanID := sc.TraceID().String(), sc.SpanID().String()
+		if short {
+			traceID, spanID = traceID[:shortIDLen], spanID[:shortIDLen]
+		}
+		return []slog.Attr{
+			slog.String(TraceIDKey, traceID),
+			slog.String(SpanIDKey, spanID),
+		}
+	}
+}
+
+// Configure modifies hopts, so the handlers created with them log the
+// trace and span IDs of the span stored in the context passed to
+// Handle. The attributes returned by the ContextAttrs function
+// previously set in hopts, if any, are kept. If opts is nil, the
+// default options are used.
+func Configure(hopts *clilog.HandlerOptions, opts *Options) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	fn := ContextAttrs(opts)
+	if prev := hopts.ContextAttrs; prev != nil {
+		hopts.ContextAttrs = func(ctx context.Context) []slog.Attr {
+			return append(slices.Clip(prev(ctx)), fn(ctx)...)
+		}
+	} else {
+		hopts.ContextAttrs = fn
+	}
+	if opts.Dim {
+		hopts.DimKeys = append(hopts.DimKeys, TraceIDKey, SpanIDKey)
+	}
+}
@@ -0,0 +1,92 @@
+package clilogotel
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jroimartin/clilog"
+)
+
+// spanContext returns a context containing a valid span context.
+func spanContext(t *testing.T) context.Context {
+	traceID, err := trace.TraceIDFromHex("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("invalid trace ID: %v", err)
+	}
+	spanID, err := trace.SpanIDFromHex("0123456789abcdef")
+	if err != nil {
+		t.Fatalf("invalid span ID: %v", err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *Options
+		want string
+	}{
+		{
+			name: "default",
+			want: "INFO message trace_id=0123456789abcdef0123456789abcdef span_id=0123456789abcdef\n",
+		},
+		{
+			name: "Short",
+			opts: &Options{Short: true},
+			want: "INFO message trace_id=01234567 span_id=01234567\n",
+		},
+		{
+			name: "Dim",
+			opts: &Options{Short: true, Dim: true},
+			want: "INFO message \x1b[2mtrace_id=01234567\x1b[0m \x1b[2mspan_id=01234567\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hopts := clilog.HandlerOptions{
+				OmitTime: true,
+				Color:    clilog.ColorAlways,
+				Theme:    &clilog.Theme{Dim: "\x1b[2m"},
+			}
+			Configure(&hopts, tt.opts)
+
+			var buf bytes.Buffer
+			logger := slog.New(clilog.NewCLIHandler(&buf, &hopts))
+			logger.InfoContext(spanContext(t), "message")
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextAttrs_noSpan(t *testing.T) {
+	if attrs := ContextAttrs(nil)(context.Background()); attrs != nil {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+}
+
+func TestConfigure_keepContextAttrs(t *testing.T) {
+	hopts := clilog.HandlerOptions{
+		OmitTime: true,
+		ContextAttrs: func(ctx context.Context) []slog.Attr {
+			return []slog.Attr{slog.String("run_id", "42")}
+		},
+	}
+	Configure(&hopts, &Options{Short: true})
+
+	var buf bytes.Buffer
+	logger := slog.New(clilog.NewCLIHandler(&buf, &hopts))
+	logger.InfoContext(spanContext(t), "message")
+
+	if got, want := buf.String(), "INFO message run_id=42 trace_id=01234567 span_id=01234567\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Package clilogprom exports the log volume of a clilog handler as
+// Prometheus metrics. It is a separate module, so programs that do
+// not use Prometheus do not depend on it.
+package clilogprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jroimartin/clilog"
+)
+
+// Collector is a [prometheus.Collector] that exports the number of
+// records per level and the number of write errors of a
+// [clilog.CLIHandler] and the handlers derived from it.
+type Collector struct {
+	h           *clilog.CLIHandler
+	records     *prometheus.Desc
+	writeErrors *prometheus.Desc
+}
+
+// NewCollector returns a new [Collector] for h. The metrics are named
+// "<namespace>_log_records_total", with a "level" label, and
+// "<namespace>_log_write_errors_total". If namespace is empty, the
+// metrics are not prefixed.
+func NewCollector(h *clilog.CLIHandler, namespace string) *Collector {
+	return &Collector{
+		h: h,
+		records: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "log", "records_total"),
+			"Number of log records handled per level.",
+			[]string{"level"}, nil,
+		),
+		writeErrors: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "log", "write_errors_total"),
+			"Number of errors writing log records.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe sends the descriptors of the metrics to ch.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.records
+	ch <- c.writeErrors
+}
+
+// Collect sends the current values of the metrics to ch.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.h.Stats()
+	for _, r := range []struct {
+		level string
+		n     uint64
+	}{
+		{"trace", s.Trace},
+		{"debug", s.Debug},
+		{"info", s.Info},
+		{"notice", s.Notice},
+		{"warn", s.Warn},
+		{"error", s.Error},
+		{"fatal", s.Fatal},
+	} {
+		ch <- prometheus.MustNewConstMetric(c.records, prometheus.CounterValue, float64(r.n), r.level)
+	}
+	ch <- prometheus.MustNewConstMetric(c.writeErrors, prometheus.CounterValue, float64(c.h.Errors()))
+}
@@ -0,0 +1,38 @@
+package clilogprom
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/jroimartin/clilog"
+)
+
+func TestCollector(t *testing.T) {
+	h := clilog.NewCLIHandler(&bytes.Buffer{}, nil)
+	logger := slog.New(h)
+	logger.Info("info")
+	logger.Error("error")
+	logger.Error("error")
+
+	want := `
+# HELP tool_log_records_total Number of log records handled per level.
+# TYPE tool_log_records_total counter
+tool_log_records_total{level="debug"} 0
+tool_log_records_total{level="error"} 2
+tool_log_records_total{level="fatal"} 0
+tool_log_records_total{level="info"} 1
+tool_log_records_total{level="notice"} 0
+tool_log_records_total{level="trace"} 0
+tool_log_records_total{level="warn"} 0
+# HELP tool_log_write_errors_total Number of errors writing log records.
+# TYPE tool_log_write_errors_total counter
+tool_log_write_errors_total 0
+`
+	if err := testutil.CollectAndCompare(NewCollector(h, "tool"), strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,163 @@
+// Package clilogtest provides utilities for testing the log output of
+// programs using [slog].
+package clilogtest
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CaptureHandler is a [slog.Handler] that records in memory the
+// records it handles, so tests can assert on them. The attributes
+// added with WithAttrs and the groups opened with WithGroup are
+// included in the captured records. The handlers derived from a
+// CaptureHandler share its records.
+type CaptureHandler struct {
+	level  slog.Leveler
+	attrs  []slog.Attr // attributes added with WithAttrs
+	groups []string    // groups opened with WithGroup
+	rec    *recorder
+}
+
+// recorder holds the records captured by a CaptureHandler and the
+// handlers derived from it.
+type recorder struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+// NewCaptureHandler returns a new [CaptureHandler] that captures the
+// records with a level greater than or equal to level. If level is
+// nil, all records are captured.
+func NewCaptureHandler(level slog.Leveler) *CaptureHandler {
+	return &CaptureHandler{level: level, rec: &recorder{}}
+}
+
+// Enabled reports whether the handler captures records at the given
+// level.
+func (h *CaptureHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.level == nil || level >= h.level.Level()
+}
+
+// Handle captures r.
+func (h *CaptureHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(h.attrs...)
+
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	nr.AddAttrs(h.nest(attrs)...)
+
+	h.rec.mu.Lock()
+	defer h.rec.mu.Unlock()
+	h.rec.records = append(h.rec.records, nr)
+	return nil
+}
+
+// WithAttrs returns a new [CaptureHandler] whose captured records
+// include attrs.
+func (h *CaptureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(slices.Clip(h.attrs), h.nest(attrs)...)
+	return &h2
+}
+
+// WithGroup returns a new [CaptureHandler] that qualifies the
+// attributes of the captured records with the provided group.
+func (h *CaptureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	return &h2
+}
+
+// nest returns attrs nested in the groups opened with WithGroup. Like
+// the handlers of the slog package, it returns nil if attrs is empty.
+func (h *CaptureHandler) nest(attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: h.groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// Records returns a copy of the captured records.
+func (h *CaptureHandler) Records() []slog.Record {
+	h.rec.mu.Lock()
+	defer h.rec.mu.Unlock()
+
+	records := make([]slog.Record, len(h.rec.records))
+	for i, r := range h.rec.records {
+		records[i] = r.Clone()
+	}
+	return records
+}
+
+// Reset discards the captured records.
+func (h *CaptureHandler) Reset() {
+	h.rec.mu.Lock()
+	defer h.rec.mu.Unlock()
+	h.rec.records = nil
+}
+
+// Contains reports whether a record with the provided level and a
+// message containing substr has been captured.
+func (h *CaptureHandler) Contains(level slog.Level, substr string) bool {
+	h.rec.mu.Lock()
+	defer h.rec.mu.Unlock()
+
+	for _, r := range h.rec.records {
+		if r.Level == level && strings.Contains(r.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AttrsOf returns the attributes of the first captured record with the
+// provided message. Attribute values are resolved and the attributes
+// within groups are keyed by their dotted path (e.g. "req.method"). It
+// returns nil if no record has the message.
+func (h *CaptureHandler) AttrsOf(msg string) map[string]slog.Value {
+	h.rec.mu.Lock()
+	defer h.rec.mu.Unlock()
+
+	for _, r := range h.rec.records {
+		if r.Message != msg {
+			continue
+		}
+		attrs := make(map[string]slog.Value)
+		r.Attrs(func(a slog.Attr) bool {
+			flatten(attrs, "", a)
+			return true
+		})
+		return attrs
+	}
+	return nil
+}
+
+// flatten adds to m the attribute a qualified by prefix. Groups are
+// flattened recursively.
+func flatten(m map[string]slog.Value, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		m[prefix+a.Key] = v
+		return
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, a := range v.Group() {
+		flatten(m, prefix, a)
+	}
+}
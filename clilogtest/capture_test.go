@@ -0,0 +1,105 @@
+package clilogtest
+
+import (
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+)
+
+func TestCaptureHandler(t *testing.T) {
+	h := NewCaptureHandler(slog.LevelInfo)
+	logger := slog.New(h)
+
+	logger.Debug("ignored")
+	logger.Info("request", "method", "GET")
+	logger.With("user", "alice").WithGroup("req").Warn("slow request", "path", "/", slog.Group("t", "ms", 250))
+	logger.WithGroup("empty").Error("failed")
+
+	if got := len(h.Records()); got != 3 {
+		t.Fatalf("unexpected number of records: %v", got)
+	}
+
+	if !h.Contains(slog.LevelWarn, "slow") {
+		t.Errorf("warning not found")
+	}
+	if h.Contains(slog.LevelInfo, "slow") {
+		t.Errorf("unexpected info record found")
+	}
+	if h.Contains(slog.LevelDebug, "ignored") {
+		t.Errorf("unexpected debug record found")
+	}
+
+	attrs := h.AttrsOf("slow request")
+	want := map[string]string{"user": "alice", "req.path": "/", "req.t.ms": "250"}
+	if len(attrs) != len(want) {
+		t.Errorf("unexpected attrs: %v", attrs)
+	}
+	for k, v := range want {
+		if got := attrs[k].String(); got != v {
+			t.Errorf("unexpected value of %q: got %q, want %q", k, got, v)
+		}
+	}
+
+	if attrs := h.AttrsOf("failed"); attrs == nil || len(attrs) != 0 {
+		t.Errorf("unexpected attrs: %v", attrs)
+	}
+	if attrs := h.AttrsOf("unknown"); attrs != nil {
+		t.Errorf("unexpected attrs: %v", attrs)
+	}
+
+	h.Reset()
+	if got := len(h.Records()); got != 0 {
+		t.Errorf("unexpected number of records after reset: %v", got)
+	}
+}
+
+func TestCaptureHandler_slogtest(t *testing.T) {
+	h := NewCaptureHandler(nil)
+	results := func() []map[string]any {
+		var ms []map[string]any
+		for _, r := range h.Records() {
+			m := map[string]any{
+				slog.LevelKey:   r.Level,
+				slog.MessageKey: r.Message,
+			}
+			if !r.Time.IsZero() {
+				m[slog.TimeKey] = r.Time
+			}
+			r.Attrs(func(a slog.Attr) bool {
+				addAttr(m, a)
+				return true
+			})
+			ms = append(ms, m)
+		}
+		return ms
+	}
+	if err := slogtest.TestHandler(h, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// addAttr adds a to m, converting groups into nested maps. Groups
+// with the same key are merged.
+func addAttr(m map[string]any, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		if a.Key != "" {
+			m[a.Key] = v.Any()
+		}
+		return
+	}
+	gm := m
+	if a.Key != "" {
+		if len(v.Group()) == 0 {
+			return
+		}
+		var ok bool
+		if gm, ok = m[a.Key].(map[string]any); !ok {
+			gm = map[string]any{}
+			m[a.Key] = gm
+		}
+	}
+	for _, a := range v.Group() {
+		addAttr(gm, a)
+	}
+}
@@ -0,0 +1,87 @@
+package clilogtest
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jroimartin/clilog"
+)
+
+// update is namespaced, so it does not collide with the flags of the
+// test packages importing clilogtest.
+var update = flag.Bool("clilogtest.update", false, "update the golden files of clilogtest.Golden")
+
+// GoldenTime is the time of the records rendered by [Render] and
+// [Golden].
+var GoldenTime = time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+// GoldenSource is the source code position of the records rendered by
+// [Render] and [Golden].
+var GoldenSource = &slog.Source{
+	Function: "main.main",
+	File:     "/src/cmd/tool/main.go",
+	Line:     1,
+}
+
+// Render returns the output of a [clilog.CLIHandler] created with opts
+// for the records logged by fn. The time and the source code position
+// of the records are replaced by GoldenTime and GoldenSource, so the
+// output is deterministic. The ReplaceAttr function of opts, if any,
+// is called after replacing them.
+func Render(opts *clilog.HandlerOptions, fn func(logger *slog.Logger)) string {
+	var o clilog.HandlerOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.Now = func() time.Time { return GoldenTime }
+	replace := o.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.SourceKey {
+			// Only the built-in attribute is replaced, not user
+			// attributes with the same key.
+			if _, ok := a.Value.Any().(*slog.Source); ok {
+				a.Value = slog.AnyValue(GoldenSource)
+			}
+		}
+		if replace != nil {
+			a = replace(groups, a)
+		}
+		return a
+	}
+
+	var buf bytes.Buffer
+	fn(slog.New(clilog.NewCLIHandler(&buf, &o)))
+	return buf.String()
+}
+
+// Golden compares the output rendered by [Render] with the golden file
+// testdata/name.golden. If the test binary is run with the
+// -clilogtest.update flag, the golden file is written instead.
+func Golden(t testing.TB, name string, opts *clilog.HandlerOptions, fn func(logger *slog.Logger)) {
+	t.Helper()
+
+	got := Render(opts, fn)
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("could not create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("could not update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read golden file (run with -clilogtest.update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %v:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package clilogtest
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jroimartin/clilog"
+)
+
+func TestRender(t *testing.T) {
+	got := Render(&clilog.HandlerOptions{AddSource: true, SourceFormat: clilog.SourceShort}, func(logger *slog.Logger) {
+		logger.Info("message", "a", 1)
+	})
+	want := "2006-01-02T15:04:05Z INFO main.go:1 message a=1\n"
+	if got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestRender_sourceAttr(t *testing.T) {
+	got := Render(&clilog.HandlerOptions{OmitTime: true}, func(logger *slog.Logger) {
+		logger.Info("message", "source", "cache")
+	})
+	want := "INFO message source=cache\n"
+	if got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	opts := &clilog.HandlerOptions{
+		Level:     clilog.LevelTrace,
+		AddSource: true,
+		Multiline: true,
+	}
+	Golden(t, "basic", opts, func(logger *slog.Logger) {
+		logger.Debug("starting", "version", "1.0.0")
+		logger.With("user", "alice").WithGroup("req").Info("request", "method", "GET", "path", "/")
+		logger.Warn("slow request", "elapsed", 1500*time.Millisecond)
+		logger.Error("request failed", "err", errors.New("connection refused"), "body", "line 1\nline 2")
+	})
+}
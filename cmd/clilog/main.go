@@ -0,0 +1,115 @@
+// Clilog reads log lines in JSON or logfmt format and prints them in
+// the human readable format of the clilog package.
+//
+// Usage:
+//
+//	clilog [flags] [file ...]
+//
+// If no files are provided, clilog reads from the standard input. For
+// instance:
+//
+//	server 2>&1 | clilog -level warn -drop trace_id
+//
+// The lines that are not JSON objects nor logfmt lines are printed
+// unmodified. The flags are:
+//
+//	-level level
+//		minimum level of the printed records (default "trace")
+//	-keys keys
+//		comma-separated list of the keys of the printed attributes
+//	-drop keys
+//		comma-separated list of the keys of the omitted attributes
+//	-time format
+//		timestamp format: "off", "rfc3339", "datetime", "timeonly",
+//		"elapsed", etc., or a time layout without commas
+//	-color mode
+//		color mode: "auto", "always" or "never" (default "auto")
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jroimartin/clilog"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the provided arguments and returns its
+// exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("clilog", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: clilog [flags] [file ...]")
+		fs.PrintDefaults()
+	}
+	level := fs.String("level", "trace", "minimum `level` of the printed records")
+	keys := fs.String("keys", "", "comma-separated list of the `keys` of the printed attributes")
+	drop := fs.String("drop", "", "comma-separated list of the `keys` of the omitted attributes")
+	timeFmt := fs.String("time", "", "timestamp `format` (off, rfc3339, datetime, timeonly, elapsed, etc.)")
+	color := fs.String("color", "auto", "color `mode` (auto, always, never)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	settings := []string{"format=text", "level=" + *level, "color=" + *color}
+	if *timeFmt != "" {
+		settings = append(settings, "time="+*timeFmt)
+	}
+	opts, err := clilog.ParseConfig(strings.Join(settings, ","))
+	if err != nil {
+		fmt.Fprintf(stderr, "clilog: %v\n", err)
+		return 2
+	}
+	opts.OnlyKeys = splitKeys(*keys)
+	opts.DropKeys = splitKeys(*drop)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	status := 0
+	for _, file := range files {
+		if err := prettyPrintFile(file, stdin, stdout, &opts); err != nil {
+			fmt.Fprintf(stderr, "clilog: %v\n", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// prettyPrintFile pretty prints the log lines of file. If file is
+// "-", the lines are read from stdin.
+func prettyPrintFile(file string, stdin io.Reader, stdout io.Writer, opts *clilog.HandlerOptions) error {
+	if file == "-" {
+		return clilog.PrettyPrint(stdin, stdout, opts)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return clilog.PrettyPrint(f, stdout, opts)
+}
+
+// splitKeys splits a comma-separated list of keys. It returns nil if
+// s is empty.
+func splitKeys(s string) []string {
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
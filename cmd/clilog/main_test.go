@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testInput = `{"time":"2023-09-20T12:24:43Z","level":"DEBUG","msg":"connecting","addr":"localhost:80"}
+time=2023-09-20T12:24:44Z level=WARN msg="slow response" ms=1200 trace_id=abc
+not a log line
+`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "defaults",
+			args: []string{"-time", "timeonly"},
+			want: "12:24:43 DEBUG connecting addr=localhost:80\n" +
+				"12:24:44 WARN slow response ms=1200 trace_id=abc\n" +
+				"not a log line\n",
+		},
+		{
+			name: "level",
+			args: []string{"-time", "off", "-level", "warn"},
+			want: "WARN slow response ms=1200 trace_id=abc\nnot a log line\n",
+		},
+		{
+			name: "keys",
+			args: []string{"-time", "off", "-keys", "addr, ms"},
+			want: "DEBUG connecting addr=localhost:80\nWARN slow response ms=1200\nnot a log line\n",
+		},
+		{
+			name: "drop",
+			args: []string{"-time", "off", "-drop", "trace_id"},
+			want: "DEBUG connecting addr=localhost:80\nWARN slow response ms=1200\nnot a log line\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CI", "")
+			t.Setenv("JOURNAL_STREAM", "")
+
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-color", "never"}, tt.args...)
+			if code := run(args, strings.NewReader(testInput), &stdout, &stderr); code != 0 {
+				t.Fatalf("unexpected exit code %v: %v", code, stderr.String())
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_files(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(`{"level":"ERROR","msg":"failed"}`+"\n"), 0o644); err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-color", "never", "-time", "off", path, filepath.Join(t.TempDir(), "missing.log")}
+	if code := run(args, strings.NewReader(""), &stdout, &stderr); code != 1 {
+		t.Errorf("unexpected exit code: %v", code)
+	}
+	if got, want := stdout.String(), "ERROR failed\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+	if !strings.Contains(stderr.String(), "missing.log") {
+		t.Errorf("missing error: %q", stderr.String())
+	}
+}
+
+func TestRun_invalidFlags(t *testing.T) {
+	tests := [][]string{
+		{"-level", "loud"},
+		{"-color", "sometimes"},
+		{"-unknown"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, strings.NewReader(""), &stdout, &stderr); code != 2 {
+			t.Errorf("%q: unexpected exit code: %v", args, code)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package clilog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// ANSI escape sequences used by the default theme.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiDim     = "\x1b[2m"
+	ansiBoldRed = "\x1b[1;31m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiGrey    = "\x1b[90m"
+)
+
+// ColorMode controls whether a [CLIHandler] colorizes its output.
+type ColorMode int
+
+// Color modes.
+const (
+	// ColorNever disables colors.
+	ColorNever ColorMode = iota
+
+	// ColorAuto enables colors if the output is a terminal, as
+	// reported by ColorEnabled.
+	ColorAuto
+
+	// ColorAlways enables colors unconditionally.
+	ColorAlways
+)
+
+// String returns a name for the color mode.
+func (m ColorMode) String() string {
+	switch m {
+	case ColorNever:
+		return "never"
+	case ColorAuto:
+		return "auto"
+	case ColorAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("ColorMode(%d)", int(m))
+	}
+}
+
+// ColorEnabled reports whether output written to w should be
+// colorized. It is the decision made by a [CLIHandler] configured
+// with ColorAuto.
+//
+// The following environment variables are honored, in order of
+// precedence:
+//
+//   - NO_COLOR: if set to a non-empty value, colors are disabled.
+//   - CLICOLOR_FORCE: if set to a non-empty value other than "0",
+//     colors are enabled even if w is not a terminal.
+//   - CLICOLOR: if set to "0", colors are disabled.
+//
+// Otherwise, colors are enabled if w is a terminal.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return true
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// colorEnabled reports whether output written to w must be colorized
+// according to mode.
+func colorEnabled(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAuto:
+		return ColorEnabled(w)
+	case ColorAlways:
+		return true
+	default:
+		return false
+	}
+}
+
+// Theme defines the styles used by a [CLIHandler] to colorize its
+// output. Each style is an ANSI escape sequence (e.g. "\x1b[31m")
+// that is written before the corresponding token. An empty style
+// leaves the token unstyled.
+type Theme struct {
+	// Time is the style of the timestamp.
+	Time string
+
+	// Debug, Info, Warn and Error are the styles of the level
+	// token. Levels between named levels use the style of the
+	// closest lower one.
+	Debug string
+	Info  string
+	Warn  string
+	Error string
+
+	// Trace, Notice and Fatal are the styles of the level token
+	// for LevelTrace, LevelNotice and LevelFatal. If empty, the
+	// style of the closest standard level is used: Debug for
+	// Trace, Info for Notice and Error for Fatal.
+	Trace  string
+	Notice string
+	Fatal  string
+
+	// ErrorAttr is the style of the attributes whose value is an
+	// error.
+	ErrorAttr string
+
+	// Dim is the style of the attributes listed in
+	// HandlerOptions.DimKeys.
+	Dim string
+}
+
+// DefaultTheme is the [Theme] used when [HandlerOptions.Theme] is
+// nil.
+var DefaultTheme = Theme{
+	Time:  ansiDim,
+	Debug: ansiGrey,
+	Info:  ansiBlue,
+	Warn:  ansiYellow,
+	Error: ansiRed,
+	Trace: ansiGrey,
+	Fatal: ansiBoldRed,
+
+	ErrorAttr: ansiRed,
+	Dim:       ansiDim,
+}
+
+// levelStyle returns the style of the provided level.
+func (t *Theme) levelStyle(level slog.Level) string {
+	return selectLevel(level, levelValues{
+		trace:  t.Trace,
+		debug:  t.Debug,
+		info:   t.Info,
+		notice: t.Notice,
+		warn:   t.Warn,
+		error:  t.Error,
+		fatal:  t.Fatal,
+	})
+}
+
+// writeStyled writes s to b using the provided style. If style is
+// empty, s is written verbatim.
+func writeStyled(b *buffer, style, s string) {
+	b.WriteString(style)
+	b.WriteString(s)
+	endStyle(b, style)
+}
+
+// endStyle resets the attributes set by style, if any.
+func endStyle(b *buffer, style string) {
+	if style != "" {
+		b.WriteString(ansiReset)
+	}
+}
@@ -0,0 +1,163 @@
+package clilog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestTheme_levelStyle(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{LevelTrace - 4, ansiGrey},
+		{LevelTrace, ansiGrey},
+		{slog.LevelDebug, ansiGrey},
+		{slog.LevelInfo, ansiBlue},
+		{LevelNotice, ansiBlue},
+		{slog.LevelWarn, ansiYellow},
+		{slog.LevelError, ansiRed},
+		{slog.LevelError + 2, ansiRed},
+		{LevelFatal, ansiBoldRed},
+		{LevelFatal + 4, ansiBoldRed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := DefaultTheme.levelStyle(tt.level); got != tt.want {
+				t.Errorf("unexpected style: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("could not create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	tests := []struct {
+		name string
+		w    io.Writer
+		mode ColorMode
+		want bool
+	}{
+		{
+			name: "never",
+			w:    w,
+			mode: ColorNever,
+			want: false,
+		},
+		{
+			name: "always",
+			w:    w,
+			mode: ColorAlways,
+			want: true,
+		},
+		{
+			name: "auto pipe",
+			w:    w,
+			mode: ColorAuto,
+			want: false,
+		},
+		{
+			name: "auto buffer",
+			w:    &bytes.Buffer{},
+			mode: ColorAuto,
+			want: false,
+		},
+	}
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := colorEnabled(tt.w, tt.mode); got != tt.want {
+				t.Errorf("unexpected result: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorEnabled_env(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{
+			name: "no env",
+			env:  nil,
+			want: false,
+		},
+		{
+			name: "CLICOLOR_FORCE",
+			env:  map[string]string{"CLICOLOR_FORCE": "1"},
+			want: true,
+		},
+		{
+			name: "CLICOLOR_FORCE zero",
+			env:  map[string]string{"CLICOLOR_FORCE": "0"},
+			want: false,
+		},
+		{
+			name: "CLICOLOR_FORCE empty",
+			env:  map[string]string{"CLICOLOR_FORCE": ""},
+			want: false,
+		},
+		{
+			name: "NO_COLOR,CLICOLOR_FORCE",
+			env:  map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"},
+			want: false,
+		},
+		{
+			name: "CLICOLOR zero,CLICOLOR_FORCE",
+			env:  map[string]string{"CLICOLOR": "0", "CLICOLOR_FORCE": "1"},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+				t.Setenv(k, "")
+				os.Unsetenv(k)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := ColorEnabled(&bytes.Buffer{}); got != tt.want {
+				t.Errorf("unexpected result: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTheme_levelStyle_fallback(t *testing.T) {
+	theme := Theme{Debug: "debug", Info: "info", Error: "error"}
+
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{LevelTrace, "debug"},
+		{LevelNotice, "info"},
+		{LevelFatal, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(LevelString(tt.level), func(t *testing.T) {
+			if got := theme.levelStyle(tt.level); got != tt.want {
+				t.Errorf("unexpected style: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
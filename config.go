@@ -0,0 +1,253 @@
+package clilog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by [OptionsFromEnv].
+const (
+	EnvLevel  = "CLILOG_LEVEL"
+	EnvFormat = "CLILOG_FORMAT"
+	EnvColor  = "CLILOG_COLOR"
+	EnvTime   = "CLILOG_TIME"
+)
+
+// OptionsFromEnv returns the options returned by [DetectEnvironment]
+// modified according to the following environment variables, so the
+// end users of a program can tune its logs without the program
+// exposing flags:
+//
+//   - CLILOG_LEVEL: minimum level, parsed by [ParseLevel] (e.g.
+//     "debug" or "warn+1").
+//   - CLILOG_FORMAT: output format: "text", "logfmt", "auto", "ci",
+//     "github", "gitlab", "azure" or "journald".
+//   - CLILOG_COLOR: color mode: "auto", "always" or "never".
+//   - CLILOG_TIME: timestamp format: "off" omits timestamps;
+//     "rfc3339", "rfc3339nano", "datetime", "dateonly", "timeonly",
+//     "kitchen", "unix", "unixmilli", "unixmicro", "unixnano" and
+//     "elapsed" select the corresponding format; any other value
+//     is used as a [time.Time.Format] layout.
+//
+// Empty variables are ignored. The precedence, from lowest to
+// highest, is: the detected environment, the CLILOG_* variables and
+// the fields set by the program on the returned options. That is,
+// programs that want to let users override a setting must not set it
+// after calling OptionsFromEnv.
+//
+// Invalid values are ignored and reported in the returned error,
+// along with the options built from the valid ones.
+func OptionsFromEnv() (HandlerOptions, error) {
+	opts := DetectEnvironment()
+	var errs []error
+	for _, env := range []struct {
+		key   string
+		apply func(*HandlerOptions, string) error
+	}{
+		{EnvLevel, setLevel},
+		{EnvFormat, setFormat},
+		{EnvColor, setColor},
+		{EnvTime, setTime},
+	} {
+		v := os.Getenv(env.key)
+		if v == "" {
+			continue
+		}
+		if err := env.apply(&opts, v); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", env.key, err))
+		}
+	}
+	return opts, errors.Join(errs...)
+}
+
+// setLevel sets the level of opts parsed from s.
+func setLevel(opts *HandlerOptions, s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	opts.Level = level
+	return nil
+}
+
+// setFormat sets the output format of opts named by s.
+func setFormat(opts *HandlerOptions, s string) error {
+	for _, f := range []OutputFormat{FormatText, FormatLogfmt, FormatAuto, FormatCI, FormatGitHub, FormatGitLab, FormatAzure, FormatJournald} {
+		if strings.EqualFold(s, f.String()) {
+			opts.Format = f
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q", s)
+}
+
+// setColor sets the color mode of opts named by s.
+func setColor(opts *HandlerOptions, s string) error {
+	for _, m := range []ColorMode{ColorAuto, ColorAlways, ColorNever} {
+		if strings.EqualFold(s, m.String()) {
+			opts.Color = m
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown color mode %q", s)
+}
+
+// timeFormats are the named timestamp formats accepted by setTime.
+var timeFormats = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"datetime":    time.DateTime,
+	"dateonly":    time.DateOnly,
+	"timeonly":    time.TimeOnly,
+	"kitchen":     time.Kitchen,
+	"unix":        TimeUnix,
+	"unixmilli":   TimeUnixMilli,
+	"unixmicro":   TimeUnixMicro,
+	"unixnano":    TimeUnixNano,
+	"elapsed":     TimeElapsed,
+}
+
+// setTime sets the timestamp format of opts described by s. If s is
+// "off", timestamps are omitted.
+func setTime(opts *HandlerOptions, s string) error {
+	if strings.EqualFold(s, "off") {
+		opts.OmitTime = true
+		return nil
+	}
+	opts.OmitTime = false
+	if layout, ok := timeFormats[strings.ToLower(s)]; ok {
+		opts.TimeFormat = layout
+		return nil
+	}
+	opts.TimeFormat = s
+	return nil
+}
+
+// ParseConfig parses a compact configuration string, such as
+// "level=debug,color=never,time=off,source", and returns the options
+// returned by [DetectEnvironment] modified accordingly. It allows
+// programs to configure logging with a single command line flag.
+//
+// The string is a comma-separated list of settings. The keys level,
+// format, color and time accept the values of the corresponding
+// variables documented in [OptionsFromEnv]. The following keys are
+// also supported:
+//
+//   - source: includes the source code position. It accepts a
+//     boolean or the name of a source format: "long", "short" or
+//     "package".
+//   - quote: quoting mode: "when-needed", "always" or "never".
+//   - multiline: renders multi-line values as indented blocks. It
+//     accepts an optional boolean.
+//   - precision: timestamp precision: "layout", "seconds",
+//     "milliseconds" or "microseconds" (see
+//     [HandlerOptions.TimePrecision]).
+//   - tz: time zone of the timestamps: "utc", "local" or the name
+//     of a location of the IANA Time Zone database (e.g.
+//     "Europe/Madrid").
+//
+// The keys source and multiline without value are equivalent to
+// key=true. Empty settings are ignored.
+func ParseConfig(s string) (HandlerOptions, error) {
+	opts := DetectEnvironment()
+	if strings.TrimSpace(s) == "" {
+		return opts, nil
+	}
+	for _, setting := range strings.Split(s, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(setting, "=")
+		key = strings.ToLower(key)
+		if !ok {
+			switch key {
+			case "source", "multiline":
+				value = "true"
+			case "level", "format", "color", "time", "quote", "precision", "tz":
+				return HandlerOptions{}, fmt.Errorf("invalid config setting %q: missing value", setting)
+			}
+		}
+		if err := setConfig(&opts, key, value); err != nil {
+			return HandlerOptions{}, fmt.Errorf("invalid config setting %q: %w", setting, err)
+		}
+	}
+	return opts, nil
+}
+
+// setConfig sets the option of opts identified by key.
+func setConfig(opts *HandlerOptions, key, value string) error {
+	switch key {
+	case "level":
+		return setLevel(opts, value)
+	case "format":
+		return setFormat(opts, value)
+	case "color":
+		return setColor(opts, value)
+	case "time":
+		return setTime(opts, value)
+	case "source":
+		for _, f := range []SourceFormat{SourceLong, SourceShort, SourcePackage} {
+			if strings.EqualFold(value, f.String()) {
+				opts.AddSource = true
+				opts.SourceFormat = f
+				return nil
+			}
+		}
+		return setBool(&opts.AddSource, value)
+	case "quote":
+		for _, m := range []QuoteMode{QuoteWhenNeeded, QuoteAlways, QuoteNever} {
+			if strings.EqualFold(value, m.String()) {
+				opts.Quote = m
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown quote mode %q", value)
+	case "multiline":
+		return setBool(&opts.Multiline, value)
+	case "precision":
+		for _, p := range []TimePrecision{PrecisionLayout, PrecisionSeconds, PrecisionMilliseconds, PrecisionMicroseconds} {
+			if strings.EqualFold(value, p.String()) {
+				opts.TimePrecision = p
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown time precision %q", value)
+	case "tz":
+		return setTimeZone(opts, value)
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+}
+
+// setTimeZone sets the time zone of opts named by s.
+func setTimeZone(opts *HandlerOptions, s string) error {
+	switch strings.ToLower(s) {
+	case "utc":
+		opts.TimeZone, opts.UseUTC = nil, true
+		return nil
+	case "local":
+		opts.TimeZone, opts.UseUTC = time.Local, false
+		return nil
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q", s)
+	}
+	opts.TimeZone, opts.UseUTC = loc, false
+	return nil
+}
+
+// setBool sets b to the boolean parsed from s.
+func setBool(b *bool, s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", s)
+	}
+	*b = v
+	return nil
+}
@@ -0,0 +1,181 @@
+package clilog
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    HandlerOptions
+		wantErr []string
+	}{
+		{
+			name: "empty",
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly},
+		},
+		{
+			name: "all",
+			env: map[string]string{
+				"CLILOG_LEVEL":  "Debug",
+				"CLILOG_FORMAT": "logfmt",
+				"CLILOG_COLOR":  "never",
+				"CLILOG_TIME":   "rfc3339nano",
+			},
+			want: HandlerOptions{Level: slog.LevelDebug, Format: FormatLogfmt, Color: ColorNever, TimeFormat: time.RFC3339Nano},
+		},
+		{
+			name: "time off",
+			env:  map[string]string{"CLILOG_TIME": "off"},
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly, OmitTime: true},
+		},
+		{
+			name: "time layout",
+			env:  map[string]string{"CLILOG_TIME": "15:04"},
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: "15:04"},
+		},
+		{
+			name: "overrides environment",
+			env:  map[string]string{"CI": "true", "NO_COLOR": "1", "CLILOG_FORMAT": "text", "CLILOG_COLOR": "always"},
+			want: HandlerOptions{Format: FormatText, Color: ColorAlways, TimeFormat: time.RFC3339, UseUTC: true},
+		},
+		{
+			name: "invalid",
+			env: map[string]string{
+				"CLILOG_LEVEL":  "loud",
+				"CLILOG_FORMAT": "xml",
+				"CLILOG_COLOR":  "always",
+			},
+			want:    HandlerOptions{Format: FormatText, Color: ColorAlways, TimeFormat: time.TimeOnly},
+			wantErr: []string{`CLILOG_LEVEL: unknown level "loud"`, `CLILOG_FORMAT: unknown format "xml"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "TF_BUILD", "TERM", "NO_COLOR", "CLICOLOR", EnvLevel, EnvFormat, EnvColor, EnvTime} {
+				t.Setenv(key, "")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := OptionsFromEnv()
+			if tt.wantErr != nil {
+				for _, want := range tt.wantErr {
+					if err == nil || !strings.Contains(err.Error(), want) {
+						t.Errorf("unexpected error: got: %v, want: %v", err, want)
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Level != tt.want.Level || got.Format != tt.want.Format || got.Color != tt.want.Color ||
+				got.TimeFormat != tt.want.TimeFormat || got.OmitTime != tt.want.OmitTime || got.UseUTC != tt.want.UseUTC {
+				t.Errorf("unexpected options: got: %+v, want: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    HandlerOptions
+		wantErr string
+	}{
+		{
+			s:    "",
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly},
+		},
+		{
+			s:    "level=debug,color=never,time=off,source",
+			want: HandlerOptions{Level: slog.LevelDebug, Format: FormatText, Color: ColorNever, TimeFormat: time.TimeOnly, OmitTime: true, AddSource: true},
+		},
+		{
+			s:    " format=logfmt , time=unix, source=short, quote=always, multiline ",
+			want: HandlerOptions{Format: FormatLogfmt, Color: ColorAuto, TimeFormat: TimeUnix, AddSource: true, SourceFormat: SourceShort, Quote: QuoteAlways, Multiline: true},
+		},
+		{
+			s:    "source=false,multiline=0",
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly},
+		},
+		{
+			s:    "level=debug,,multiline,",
+			want: HandlerOptions{Level: slog.LevelDebug, Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly, Multiline: true},
+		},
+		{
+			s:    "precision=milliseconds",
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly, TimePrecision: PrecisionMilliseconds},
+		},
+		{
+			s:       "precision=ns",
+			wantErr: `invalid config setting "precision=ns": unknown time precision "ns"`,
+		},
+		{
+			s:    "tz=UTC",
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly, UseUTC: true},
+		},
+		{
+			s:    "tz=local",
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly, TimeZone: time.Local},
+		},
+		{
+			s:       "tz=Mars/Olympus_Mons",
+			wantErr: `invalid config setting "tz=Mars/Olympus_Mons": unknown time zone "Mars/Olympus_Mons"`,
+		},
+		{
+			s:       "level=debug,verbose",
+			wantErr: `invalid config setting "verbose": unknown key "verbose"`,
+		},
+		{
+			s:       "time",
+			wantErr: `invalid config setting "time": missing value`,
+		},
+		{
+			s:       "level=debug,quote",
+			wantErr: `invalid config setting "quote": missing value`,
+		},
+		{
+			s:       "source=maybe",
+			wantErr: `invalid config setting "source=maybe": invalid boolean "maybe"`,
+		},
+		{
+			s:       "quote=sometimes",
+			wantErr: `invalid config setting "quote=sometimes": unknown quote mode "sometimes"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			for _, key := range []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "TF_BUILD", "TERM", "NO_COLOR", "CLICOLOR"} {
+				t.Setenv(key, "")
+			}
+
+			got, err := ParseConfig(tt.s)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("unexpected error: got: %v, want: %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Level != tt.want.Level || got.Format != tt.want.Format || got.Color != tt.want.Color ||
+				got.TimeFormat != tt.want.TimeFormat || got.OmitTime != tt.want.OmitTime ||
+				got.AddSource != tt.want.AddSource || got.SourceFormat != tt.want.SourceFormat ||
+				got.Quote != tt.want.Quote || got.Multiline != tt.want.Multiline ||
+				got.TimeZone != tt.want.TimeZone || got.UseUTC != tt.want.UseUTC ||
+				got.TimePrecision != tt.want.TimePrecision {
+				t.Errorf("unexpected options: got: %+v, want: %+v", got, tt.want)
+			}
+		})
+	}
+}
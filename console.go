@@ -0,0 +1,125 @@
+package clilog
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// clearLine is the escape sequence that moves the cursor to the
+// beginning of the line and clears it.
+const clearLine = "\r\x1b[2K"
+
+// Console is an [io.Writer] that owns a terminal shared by log
+// handlers and progress indicators, such as spinners or progress
+// bars. Progress indicators render a status line below the log lines
+// with SetStatus and the handlers write the records through the
+// Console, which clears the status line, writes the record and
+// repaints the status line, so they do not interleave.
+//
+// A Console exposes the file descriptor of the terminal, so handlers
+// writing to it enable colors and detect the width of the terminal as
+// if they wrote to the terminal directly. If the underlying writer is
+// not a terminal, status lines are not rendered. It is safe to use a
+// Console concurrently.
+type Console struct {
+	w   io.Writer
+	tty bool
+
+	mu     sync.Mutex
+	status string // current status line
+	shown  bool   // whether the status line is displayed
+}
+
+// NewConsole returns a new [Console] that writes to w, usually
+// [os.Stderr].
+func NewConsole(w io.Writer) *Console {
+	return &Console{w: w, tty: isTerminal(w)}
+}
+
+// IsTerminal reports whether the console is a terminal, that is,
+// whether status lines are rendered.
+func (c *Console) IsTerminal() bool {
+	return c.tty
+}
+
+// Write clears the status line, writes p and, if p ends with a
+// newline, repaints the status line below it.
+func (c *Console) Write(p []byte) (int, error) {
+	b := newBuffer()
+	defer b.free()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shown {
+		b.WriteString(clearLine)
+		c.shown = false
+	}
+	b.Write(p)
+	if c.status != "" && len(p) > 0 && p[len(p)-1] == '\n' {
+		c.appendStatus(b)
+		c.shown = true
+	}
+	if _, err := c.w.Write(*b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// SetStatus replaces the status line, which is displayed below the
+// lines written to the console until it is cleared. Only the first
+// line of s is displayed and it is truncated to the width of the
+// terminal. If s is empty, the status line is cleared. If the console
+// is not a terminal, SetStatus does nothing.
+func (c *Console) SetStatus(s string) error {
+	if !c.tty {
+		return nil
+	}
+	s, _, _ = strings.Cut(s, "\n")
+
+	b := newBuffer()
+	defer b.free()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status == "" && s == "" {
+		return nil
+	}
+	c.status = s
+	b.WriteString(clearLine)
+	c.shown = s != ""
+	if c.shown {
+		c.appendStatus(b)
+	}
+	_, err := c.w.Write(*b)
+	return err
+}
+
+// ClearStatus clears the status line. It is equivalent to
+// SetStatus("").
+func (c *Console) ClearStatus() error {
+	return c.SetStatus("")
+}
+
+// Fd returns the file descriptor of the terminal. If the underlying
+// writer does not have a file descriptor, it returns an invalid one.
+func (c *Console) Fd() uintptr {
+	if f, ok := c.w.(interface{ Fd() uintptr }); ok {
+		return f.Fd()
+	}
+	return ^uintptr(0)
+}
+
+// appendStatus appends the status line to b, truncated so it does
+// not wrap. It must be called with mu held.
+func (c *Console) appendStatus(b *buffer) {
+	mark := len(*b)
+	b.WriteString(c.status)
+	if width := terminalWidth(c.w); width > 1 {
+		line := (*b)[mark:]
+		truncateLine(&line, width-1)
+		*b = append((*b)[:mark], line...)
+	}
+}
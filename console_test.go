@@ -0,0 +1,61 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestConsole(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Console{w: &buf, tty: true}
+	logger := slog.New(NewCLIHandler(c, &HandlerOptions{OmitTime: true}))
+
+	logger.Info("first")
+	c.SetStatus("downloading 10%")
+	c.SetStatus("downloading 20%\nignored")
+	logger.Info("second")
+	c.ClearStatus()
+	c.ClearStatus()
+	logger.Info("third")
+
+	want := "INFO first\n" +
+		clearLine + "downloading 10%" +
+		clearLine + "downloading 20%" +
+		clearLine + "INFO second\ndownloading 20%" +
+		clearLine +
+		"INFO third\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestConsole_partialWrite(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Console{w: &buf, tty: true}
+
+	c.SetStatus("status")
+	c.Write([]byte("partial "))
+	c.Write([]byte("line\n"))
+
+	want := clearLine + "status" + clearLine + "partial line\nstatus"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestConsole_notTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewConsole(&buf)
+	if c.IsTerminal() {
+		t.Fatal("buffer detected as terminal")
+	}
+
+	c.SetStatus("status")
+	c.Write([]byte("line\n"))
+	c.ClearStatus()
+
+	if got, want := buf.String(), "line\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+// requestIDKey is the context key of the request IDs.
+type requestIDKey struct{}
+
+func TestCLIHandler_ContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{
+		OmitTime: true,
+		ContextAttrs: func(ctx context.Context) []slog.Attr {
+			id, ok := ctx.Value(requestIDKey{}).(string)
+			if !ok {
+				return nil
+			}
+			return []slog.Attr{slog.String("request_id", id)}
+		},
+		RedactKeys: []string{"token"},
+	}))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+	logger.InfoContext(ctx, "with id", "token", "secret")
+	logger.WithGroup("g").InfoContext(ctx, "with group", "a", 1)
+	logger.Info("without id")
+
+	want := "INFO with id token=[REDACTED] request_id=abc\n" +
+		"INFO with group g.a=1 g.request_id=abc\n" +
+		"INFO without id\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package clilog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DedupeOptions are options for a [DedupeHandler]. A zero
+// DedupeOptions consists entirely of default values.
+type DedupeOptions struct {
+	// Interval is the maximum time a repetition summary is
+	// delayed. If records are repeated for longer, a summary is
+	// written every Interval. If Interval is not positive,
+	// summaries are only written when a different record arrives
+	// or Flush is called.
+	Interval time.Duration
+}
+
+// DedupeHandler is a [slog.Handler] that collapses consecutive
+// identical records, which are those with the same level, message and
+// attributes passed to the same handler. The first record is passed
+// to the underlying handler and the repetitions are summarized by a
+// record with the same level and the message "last message repeated
+// N times", written when a different record arrives, when the
+// interval elapses or when Flush is called.
+//
+// Handlers derived from a DedupeHandler using WithAttrs or WithGroup
+// share its state, so the repetitions are detected across them.
+type DedupeHandler struct {
+	h    slog.Handler
+	opts DedupeOptions
+	d    *deduper
+}
+
+// deduper is the state shared by a DedupeHandler and the handlers
+// derived from it.
+type deduper struct {
+	mu    sync.Mutex
+	last  *DedupeHandler // handler of the last record
+	level slog.Level     // level of the last record
+	msg   string         // message of the last record
+	attrs string         // attributes of the last record
+	ctx   context.Context
+	t     time.Time   // time of the last repetition
+	n     int         // number of pending repetitions
+	timer *time.Timer // timer of the pending summary, if any
+}
+
+// NewDedupeHandler returns a new [DedupeHandler] that passes records
+// to h. If opts is nil, the default options are used.
+func NewDedupeHandler(h slog.Handler, opts *DedupeOptions) *DedupeHandler {
+	if opts == nil {
+		opts = &DedupeOptions{}
+	}
+	return &DedupeHandler{h: h, opts: *opts, d: &deduper{}}
+}
+
+// Enabled reports whether the underlying handler handles records at
+// the given level.
+func (h *DedupeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the underlying handler, unless it
+// repeats the previous one. The summary of the pending repetitions, if
+// any, is written before any different record.
+func (h *DedupeHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := recordAttrsString(r)
+
+	h.d.mu.Lock()
+	defer h.d.mu.Unlock()
+
+	d := h.d
+	if d.last == h && d.level == r.Level && d.msg == r.Message && d.attrs == attrs {
+		d.n++
+		d.t = r.Time
+		d.ctx = context.WithoutCancel(ctx)
+		if h.opts.Interval > 0 && d.timer == nil {
+			d.timer = time.AfterFunc(h.opts.Interval, func() {
+				d.mu.Lock()
+				defer d.mu.Unlock()
+				d.timer = nil
+				d.flush() //nolint:errcheck
+			})
+		}
+		return nil
+	}
+
+	err := d.flush()
+	d.last, d.level, d.msg, d.attrs = h, r.Level, r.Message, attrs
+	if herr := h.h.Handle(ctx, r); herr != nil {
+		err = herr
+	}
+	return err
+}
+
+// Flush writes the summary of the pending repetitions, if any. It
+// should be called before the program exits.
+func (h *DedupeHandler) Flush() error {
+	h.d.mu.Lock()
+	defer h.d.mu.Unlock()
+	return h.d.flush()
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both
+// the receiver's attributes and the arguments.
+func (h *DedupeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DedupeHandler{h: h.h.WithAttrs(attrs), opts: h.opts, d: h.d}
+}
+
+// WithGroup returns a new Handler with the given group appended to
+// the receiver's existing groups.
+func (h *DedupeHandler) WithGroup(name string) slog.Handler {
+	return &DedupeHandler{h: h.h.WithGroup(name), opts: h.opts, d: h.d}
+}
+
+// flush writes the summary of the pending repetitions, if any, using
+// the handler of the last record. d.mu must be held.
+func (d *deduper) flush() error {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.n == 0 {
+		return nil
+	}
+	n := d.n
+	d.n = 0
+
+	msg := "last message repeated once"
+	if n > 1 {
+		msg = fmt.Sprintf("last message repeated %d times", n)
+	}
+	r := slog.NewRecord(d.t, d.level, msg, 0)
+	if !d.last.h.Enabled(d.ctx, d.level) {
+		return nil
+	}
+	return d.last.h.Handle(d.ctx, r)
+}
+
+// recordAttrsString returns a string representation of the attributes
+// of r used to compare records.
+func recordAttrsString(r slog.Record) string {
+	var sb strings.Builder
+	r.Attrs(func(a slog.Attr) bool {
+		fmt.Fprintf(&sb, "%q=%q ", a.Key, a.Value.Resolve())
+		return true
+	})
+	return sb.String()
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *DedupeHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
@@ -0,0 +1,84 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDedupeHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewDedupeHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), nil)
+	logger := slog.New(h)
+
+	for i := 0; i < 3; i++ {
+		logger.Warn("retrying", "attempt", 1)
+	}
+	logger.Warn("retrying", "attempt", 2)
+	logger.Warn("retrying", "attempt", 2)
+	logger.With("a", 1).Warn("retrying", "attempt", 2)
+	logger.Info("done")
+	logger.Info("done")
+	logger.Info("done")
+	if err := h.Flush(); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+	if err := h.Flush(); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+
+	want := "WARN retrying attempt=1\n" +
+		"WARN last message repeated 2 times\n" +
+		"WARN retrying attempt=2\n" +
+		"WARN last message repeated once\n" +
+		"WARN retrying a=1 attempt=2\n" +
+		"INFO done\n" +
+		"INFO last message repeated 2 times\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// lockedBuffer is a [bytes.Buffer] safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDedupeHandler_Interval(t *testing.T) {
+	var buf lockedBuffer
+
+	h := NewDedupeHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), &DedupeOptions{Interval: 10 * time.Millisecond})
+	logger := slog.New(h)
+	for i := 0; i < 4; i++ {
+		logger.Error("connection refused")
+	}
+
+	want := "ERROR connection refused\nERROR last message repeated 3 times\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "repeated") {
+		if time.Now().After(deadline) {
+			t.Fatal("summary not written")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DeltaMode controls whether and where a [CLIHandler] renders the
+// time elapsed since the previous record.
+type DeltaMode int
+
+// Delta modes.
+const (
+	// DeltaOff does not render the time elapsed since the
+	// previous record.
+	DeltaOff DeltaMode = iota
+
+	// DeltaPrefix renders the time elapsed since the previous
+	// record before the level (e.g.
+	// "12:04:05 (+230ms) INFO message key=val").
+	DeltaPrefix
+
+	// DeltaSuffix renders the time elapsed since the previous
+	// record at the end of the log line (e.g.
+	// "12:04:05 INFO message key=val (+230ms)").
+	DeltaSuffix
+)
+
+// String returns a name for the delta mode.
+func (m DeltaMode) String() string {
+	switch m {
+	case DeltaOff:
+		return "off"
+	case DeltaPrefix:
+		return "prefix"
+	case DeltaSuffix:
+		return "suffix"
+	default:
+		return fmt.Sprintf("DeltaMode(%d)", int(m))
+	}
+}
+
+// deltaWidth is the minimum width of the deltas rendered with
+// DeltaPrefix, so the levels are aligned across lines.
+const deltaWidth = len("(+230ms)")
+
+// delta returns the time elapsed between the previous record handled
+// by h, or any handler sharing its state, and r. The first record is
+// compared with the creation time of the handler. It returns false if
+// r has no time.
+func (h *CLIHandler) delta(r slog.Record) (time.Duration, bool) {
+	if r.Time.IsZero() {
+		return 0, false
+	}
+	prev := h.state.last.Swap(r.Time.UnixNano())
+	return r.Time.Sub(time.Unix(0, prev)), true
+}
+
+// appendDelta appends to b the time elapsed since the previous
+// record, styled with Theme.Dim. If pad is true, the delta is padded
+// to deltaWidth characters. It reports whether anything was appended.
+func (h *CLIHandler) appendDelta(b *buffer, out *output, r slog.Record, pad bool) bool {
+	d, ok := h.delta(r)
+	if !ok {
+		return false
+	}
+	s := "(+" + taskDuration(d) + ")"
+	if d < 0 {
+		s = "(" + taskDuration(d) + ")"
+	}
+	writeStyled(b, out.style(h.opts.Theme.Dim), s)
+	if pad {
+		appendPad(b, s, deltaWidth)
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCLIHandler_Delta(t *testing.T) {
+	tests := []struct {
+		name string
+		opts HandlerOptions
+		want string
+	}{
+		{
+			name: "prefix",
+			opts: HandlerOptions{TimeFormat: time.TimeOnly, Delta: DeltaPrefix},
+			want: "12:24:43 (+0s)    INFO first a=1\n" +
+				"12:24:43 (+230ms) INFO second a=1\n" +
+				"12:24:44 (+1.5s)  INFO third a=1\n",
+		},
+		{
+			name: "suffix",
+			opts: HandlerOptions{OmitTime: true, Delta: DeltaSuffix},
+			want: "INFO first a=1 (+0s)\n" +
+				"INFO second a=1 (+230ms)\n" +
+				"INFO third a=1 (+1.5s)\n",
+		},
+		{
+			name: "message first",
+			opts: HandlerOptions{TimeFormat: time.TimeOnly, Layout: MessageFirst, Delta: DeltaPrefix},
+			want: "(+0s)    INFO first  (12:24:43) a=1\n" +
+				"(+230ms) INFO second  (12:24:43) a=1\n" +
+				"(+1.5s)  INFO third  (12:24:44) a=1\n",
+		},
+		{
+			name: "colors",
+			opts: HandlerOptions{OmitTime: true, Delta: DeltaSuffix, Color: ColorAlways, Theme: &Theme{Dim: "\x1b[2m"}},
+			want: "INFO first a=1 \x1b[2m(+0s)\x1b[0m\n" +
+				"INFO second a=1 \x1b[2m(+230ms)\x1b[0m\n" +
+				"INFO third a=1 \x1b[2m(+1.5s)\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			now := testTime
+			tt.opts.Now = func() time.Time { return now }
+			// The derived logger shares the time of the last
+			// record with the original one.
+			logger := slog.New(NewCLIHandler(&buf, &tt.opts))
+			logger.Info("first", "a", 1)
+			now = now.Add(230 * time.Millisecond)
+			logger.With("a", 1).Info("second")
+			now = now.Add(1500 * time.Millisecond)
+			logger.Info("third", "a", 1)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
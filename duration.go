@@ -0,0 +1,71 @@
+package clilog
+
+import (
+	"strconv"
+	"time"
+)
+
+// appendDuration appends to dst the duration d formatted like
+// [time.Duration.String], without allocating.
+func appendDuration(dst []byte, d time.Duration) []byte {
+	u := uint64(d)
+	if d < 0 {
+		dst = append(dst, '-')
+		u = -u
+	}
+
+	switch {
+	case u == 0:
+		return append(dst, "0s"...)
+	case u < uint64(time.Microsecond):
+		dst = strconv.AppendUint(dst, u, 10)
+		return append(dst, "ns"...)
+	case u < uint64(time.Millisecond):
+		dst = appendDecimal(dst, u, 3)
+		return append(dst, "µs"...)
+	case u < uint64(time.Second):
+		dst = appendDecimal(dst, u, 6)
+		return append(dst, "ms"...)
+	}
+
+	secs := u / uint64(time.Second)
+	hours, mins := secs/3600, secs/60%60
+	if hours > 0 {
+		dst = strconv.AppendUint(dst, hours, 10)
+		dst = append(dst, 'h')
+	}
+	if hours > 0 || mins > 0 {
+		dst = strconv.AppendUint(dst, mins, 10)
+		dst = append(dst, 'm')
+	}
+	dst = appendDecimal(dst, secs%60*uint64(time.Second)+u%uint64(time.Second), 9)
+	return append(dst, 's')
+}
+
+// appendDecimal appends to dst v/10**scale as a decimal number. The
+// trailing zeros of the fractional part are omitted, as well as the
+// decimal point if the fractional part is 0. The scale must not be
+// greater than 9.
+func appendDecimal(dst []byte, v uint64, scale int) []byte {
+	pow := uint64(1)
+	for i := 0; i < scale; i++ {
+		pow *= 10
+	}
+	dst = strconv.AppendUint(dst, v/pow, 10)
+
+	frac := v % pow
+	if frac == 0 {
+		return dst
+	}
+	var digits [9]byte
+	for i := scale - 1; i >= 0; i-- {
+		digits[i] = byte(frac%10) + '0'
+		frac /= 10
+	}
+	n := scale
+	for digits[n-1] == '0' {
+		n--
+	}
+	dst = append(dst, '.')
+	return append(dst, digits[:n]...)
+}
@@ -0,0 +1,40 @@
+package clilog
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAppendDuration(t *testing.T) {
+	tests := []time.Duration{
+		0,
+		1,
+		999,
+		time.Microsecond,
+		1500 * time.Nanosecond,
+		time.Millisecond,
+		1100 * time.Microsecond,
+		time.Second - 1,
+		time.Second,
+		time.Second + 1,
+		1500 * time.Millisecond,
+		time.Minute,
+		90 * time.Second,
+		time.Hour,
+		61 * time.Minute,
+		time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond,
+		-1500 * time.Millisecond,
+		-time.Microsecond,
+		math.MaxInt64,
+		math.MinInt64,
+	}
+
+	for _, d := range tests {
+		t.Run(d.String(), func(t *testing.T) {
+			if got, want := string(appendDuration(nil, d)), d.String(); got != want {
+				t.Errorf("unexpected duration: got: %q, want: %q", got, want)
+			}
+		})
+	}
+}
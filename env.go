@@ -0,0 +1,62 @@
+package clilog
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// DetectEnvironment returns the options recommended for the
+// environment the program is running in, according to the
+// environment variables CI, GITHUB_ACTIONS, GITLAB_CI, TF_BUILD, TERM,
+// NO_COLOR, CLICOLOR and JOURNAL_STREAM.
+//
+// On CI systems, the format is FormatCI and timestamps include the
+// date and are rendered in UTC. On GitHub Actions, GitLab CI and
+// Azure Pipelines, whose logs render ANSI escape sequences, colors
+// are enabled. Otherwise, the format is FormatText, timestamps only
+// include the time of the day and colors are enabled if the output
+// is a terminal (see ColorEnabled). If the standard error is
+// connected to the systemd journal, the format is FormatJournald.
+// Colors are always disabled if NO_COLOR is set, CLICOLOR is "0" or
+// TERM is "dumb".
+//
+// The returned options can be modified before passing them to
+// NewCLIHandler.
+func DetectEnvironment() HandlerOptions {
+	return detectEnvironment(os.Stderr)
+}
+
+// detectEnvironment implements DetectEnvironment. The format is
+// FormatJournald if w is connected to the systemd journal.
+func detectEnvironment(w io.Writer) HandlerOptions {
+	opts := HandlerOptions{
+		Format:     FormatText,
+		Color:      ColorAuto,
+		TimeFormat: time.TimeOnly,
+	}
+	if ci := detectCIFormat(); ci != FormatText || os.Getenv("CI") != "" {
+		opts.Format = FormatCI
+		opts.TimeFormat = time.RFC3339
+		opts.UseUTC = true
+		if ci != FormatText {
+			opts.Color = ColorAlways
+		}
+	}
+	if isJournal(w) {
+		opts.Format = FormatJournald
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("CLICOLOR") == "0" || os.Getenv("TERM") == "dumb" {
+		opts.Color = ColorNever
+	}
+	return opts
+}
+
+// NewAutoHandler returns a new [CLIHandler] that writes to w using
+// the options returned by [DetectEnvironment], except that the format
+// is FormatJournald only if w, instead of the standard error, is
+// connected to the systemd journal.
+func NewAutoHandler(w io.Writer) *CLIHandler {
+	opts := detectEnvironment(w)
+	return NewCLIHandler(w, &opts)
+}
@@ -0,0 +1,79 @@
+package clilog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDetectEnvironment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want HandlerOptions
+	}{
+		{
+			name: "interactive",
+			env:  map[string]string{},
+			want: HandlerOptions{Format: FormatText, Color: ColorAuto, TimeFormat: time.TimeOnly},
+		},
+		{
+			name: "NO_COLOR",
+			env:  map[string]string{"NO_COLOR": "1"},
+			want: HandlerOptions{Format: FormatText, Color: ColorNever, TimeFormat: time.TimeOnly},
+		},
+		{
+			name: "dumb terminal",
+			env:  map[string]string{"TERM": "dumb"},
+			want: HandlerOptions{Format: FormatText, Color: ColorNever, TimeFormat: time.TimeOnly},
+		},
+		{
+			name: "github",
+			env:  map[string]string{"CI": "true", "GITHUB_ACTIONS": "true"},
+			want: HandlerOptions{Format: FormatCI, Color: ColorAlways, TimeFormat: time.RFC3339, UseUTC: true},
+		},
+		{
+			name: "azure",
+			env:  map[string]string{"TF_BUILD": "True"},
+			want: HandlerOptions{Format: FormatCI, Color: ColorAlways, TimeFormat: time.RFC3339, UseUTC: true},
+		},
+		{
+			name: "github NO_COLOR",
+			env:  map[string]string{"CI": "true", "GITHUB_ACTIONS": "true", "NO_COLOR": "1"},
+			want: HandlerOptions{Format: FormatCI, Color: ColorNever, TimeFormat: time.RFC3339, UseUTC: true},
+		},
+		{
+			name: "other CI",
+			env:  map[string]string{"CI": "true"},
+			want: HandlerOptions{Format: FormatCI, Color: ColorAuto, TimeFormat: time.RFC3339, UseUTC: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "TF_BUILD", "TERM", "NO_COLOR", "CLICOLOR", "JOURNAL_STREAM"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			got := DetectEnvironment()
+			if got.Format != tt.want.Format || got.Color != tt.want.Color || got.TimeFormat != tt.want.TimeFormat || got.UseUTC != tt.want.UseUTC {
+				t.Errorf("unexpected options: got: %+v, want: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAutoHandler(t *testing.T) {
+	for _, key := range []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "TF_BUILD", "TERM", "NO_COLOR", "CLICOLOR", "JOURNAL_STREAM"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("GITHUB_ACTIONS", "true")
+
+	h := NewAutoHandler(&bytes.Buffer{})
+	if h.opts.Format != FormatGitHub {
+		t.Errorf("unexpected format: got: %v, want: %v", h.opts.Format, FormatGitHub)
+	}
+	if !h.state.out.Load().color {
+		t.Error("colors are disabled")
+	}
+}
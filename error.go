@@ -0,0 +1,44 @@
+package clilog
+
+import (
+	"errors"
+	"strings"
+)
+
+// appendErrorChain appends to b an indented block with the errors
+// wrapped by err. Nothing is appended if err does not wrap any error.
+func (h *CLIHandler) appendErrorChain(b *buffer, key string, err error) {
+	causes := unwrap(err)
+	if len(causes) == 0 {
+		return
+	}
+	b.WriteString("  " + key + ":\n")
+	for _, cause := range causes {
+		h.appendCause(b, cause, 0)
+	}
+}
+
+// appendCause appends to b the message of err, indented according to
+// its depth in the error tree, followed by the errors wrapped by it.
+func (h *CLIHandler) appendCause(b *buffer, err error, depth int) {
+	indent := strings.Repeat("  ", depth+2)
+	msg := strings.ReplaceAll(err.Error(), "\n", "\n"+indent+"  ")
+	b.WriteString(indent + "caused by: " + h.escapeBlock(msg) + "\n")
+	for _, cause := range unwrap(err) {
+		h.appendCause(b, cause, depth+1)
+	}
+}
+
+// unwrap returns the errors wrapped by err. It supports both
+// Unwrap() error and Unwrap() []error methods.
+func unwrap(err error) []error {
+	switch err := err.(type) {
+	case interface{ Unwrap() []error }:
+		return err.Unwrap()
+	default:
+		if cause := errors.Unwrap(err); cause != nil {
+			return []error{cause}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,167 @@
+package clilog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Event types of the Windows Event Log.
+const (
+	eventlogErrorType       = 0x0001
+	eventlogWarningType     = 0x0002
+	eventlogInformationType = 0x0004
+)
+
+// EventLogEventID is the event identifier of the events written by an
+// [EventLogHandler].
+const EventLogEventID = 1
+
+// Functions of advapi32.dll not provided by the syscall package.
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSourceW  = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEventW          = advapi32.NewProc("ReportEventW")
+)
+
+// EventLogHandler is a [slog.Handler] that writes records to the
+// Windows Event Log. ERROR records are logged as errors, WARN records
+// as warnings and the rest as information events. The records are
+// rendered as a single line by a [CLIHandler] without time and
+// colors, which are provided by the Event Log. To log both to the
+// console and to the Event Log, combine it with another handler using
+// [MultiHandler].
+//
+// Handlers derived from an EventLogHandler using WithAttrs or
+// WithGroup share its event source.
+type EventLogHandler struct {
+	h    *CLIHandler
+	sink *levelSink
+	src  *eventSource
+}
+
+// eventSource is a registered event source.
+type eventSource struct {
+	mu     sync.Mutex
+	handle syscall.Handle // 0 if the event source is closed
+}
+
+// NewEventLogHandler returns a new [EventLogHandler] that writes to
+// the Application log using the provided source name. The source
+// should be registered in the registry (e.g. with the New-EventLog
+// PowerShell cmdlet), so Event Viewer does not complain about the
+// missing event descriptions. If opts is nil, the default options
+// are used. If [HandlerOptions.Level] is nil, the handler assumes
+// LevelWarn. The options Color, OmitTime, Format, Multiline,
+// Expanded, ErrorChain, Overflow, LineEnding, SplitLevel,
+// WriterFor, Fallback and Template are ignored.
+func NewEventLogHandler(source string, opts *HandlerOptions) (*EventLogHandler, error) {
+	name, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := procRegisterEventSourceW.Call(0, uintptr(unsafe.Pointer(name)))
+	if r == 0 {
+		return nil, err
+	}
+	src := &eventSource{handle: syscall.Handle(r)}
+
+	o := sinkOptions(opts)
+	if o.Level == nil {
+		o.Level = slog.LevelWarn
+	}
+	sink := &levelSink{write: src.report}
+	return &EventLogHandler{h: newSinkHandler(sink, o), sink: sink, src: src}, nil
+}
+
+// Enabled reports whether the handler handles records at the given
+// level.
+func (h *EventLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle writes the record to the Event Log with the event type
+// matching its level.
+func (h *EventLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.sink.handle(ctx, h.h, r)
+}
+
+// WithAttrs returns a new EventLogHandler whose attributes consist of
+// both the receiver's attributes and the arguments.
+func (h *EventLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &EventLogHandler{h: h.h.WithAttrs(attrs).(*CLIHandler), sink: h.sink, src: h.src}
+}
+
+// WithGroup returns a new EventLogHandler with the given group
+// appended to the receiver's existing groups.
+func (h *EventLogHandler) WithGroup(name string) slog.Handler {
+	return &EventLogHandler{h: h.h.WithGroup(name).(*CLIHandler), sink: h.sink, src: h.src}
+}
+
+// Close deregisters the event source. The records handled after
+// calling Close are discarded and reported as errors. The change
+// affects the handler and every handler derived from it.
+func (h *EventLogHandler) Close() error {
+	h.src.mu.Lock()
+	defer h.src.mu.Unlock()
+
+	if h.src.handle == 0 {
+		return errEventSourceClosed
+	}
+	r, _, err := procDeregisterEventSource.Call(uintptr(h.src.handle))
+	h.src.handle = 0
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// errEventSourceClosed is returned when writing to a closed event
+// source.
+var errEventSourceClosed = errors.New("event source is closed")
+
+// report writes msg to the event log with the event type matching
+// level.
+func (s *eventSource) report(level slog.Level, msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.handle == 0 {
+		return errEventSourceClosed
+	}
+	str, err := syscall.UTF16PtrFromString(msg)
+	if err != nil {
+		return err
+	}
+	r, _, err := procReportEventW.Call(
+		uintptr(s.handle),
+		uintptr(eventType(level)),
+		0, // category
+		EventLogEventID,
+		0, // user SID
+		1, // number of strings
+		0, // size of the raw data
+		uintptr(unsafe.Pointer(&str)),
+		0, // raw data
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// eventType returns the event type matching level.
+func eventType(level slog.Level) uint16 {
+	switch {
+	case level >= slog.LevelError:
+		return eventlogErrorType
+	case level >= slog.LevelWarn:
+		return eventlogWarningType
+	default:
+		return eventlogInformationType
+	}
+}
@@ -0,0 +1,26 @@
+package clilog
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestEventType(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  uint16
+	}{
+		{LevelTrace, eventlogInformationType},
+		{slog.LevelInfo, eventlogInformationType},
+		{LevelNotice, eventlogInformationType},
+		{slog.LevelWarn, eventlogWarningType},
+		{slog.LevelError, eventlogErrorType},
+		{slog.LevelError + 4, eventlogErrorType},
+	}
+
+	for _, tt := range tests {
+		if got := eventType(tt.level); got != tt.want {
+			t.Errorf("unexpected event type for %v: got: %v, want: %v", tt.level, got, tt.want)
+		}
+	}
+}
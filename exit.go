@@ -0,0 +1,130 @@
+package clilog
+
+import (
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// exitCodes maps levels to the exit codes set by SetExitCode.
+var exitCodes = struct {
+	sync.RWMutex
+	m map[slog.Level]int
+}{m: map[slog.Level]int{LevelFatal: 1}}
+
+// SetExitCode sets the exit code used by [Exit] for the records with
+// the provided level. The exit code of a level is the one of the
+// highest level with an exit code that is lower or equal than it. If
+// there is no such level, the exit code is 1. By default, only
+// LevelFatal has an exit code, which is 1. It is safe to call
+// SetExitCode concurrently with Exit.
+func SetExitCode(level slog.Level, code int) {
+	exitCodes.Lock()
+	defer exitCodes.Unlock()
+
+	exitCodes.m[level] = code
+}
+
+// osExit terminates the program. It is a variable, so it can be
+// replaced in tests.
+var osExit = os.Exit
+
+// Fatal logs a record at LevelFatal and terminates the program. It is
+// equivalent to Exit(logger, LevelFatal, msg, args...).
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	exit(logger, callerPC(0), LevelFatal, msg, argsToAttrs(args))
+}
+
+// Exit logs a record with the provided level, flushes the handler of
+// logger and terminates the program with the exit code of the level
+// (see [SetExitCode]). The arguments are converted to attributes as in
+// [slog.Logger.Log]. The handler is flushed if it has a Flush method,
+// such as [AsyncHandler] and [DedupeHandler], so the records buffered
+// by it are not lost. Deferred functions are not run.
+func Exit(logger *slog.Logger, level slog.Level, msg string, args ...any) {
+	exit(logger, callerPC(0), level, msg, argsToAttrs(args))
+}
+
+// Recover recovers from a panic, logs it at LevelFatal along with the
+// stack trace of the panicking goroutine, flushes the handler of
+// logger and terminates the program with the exit code of
+// LevelFatal. It must be called directly by a deferred function:
+//
+//	defer clilog.Recover(logger)
+//
+// If the goroutine is not panicking, Recover does nothing.
+func Recover(logger *slog.Logger) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	// The frames of the runtime, such as runtime.gopanic, are
+	// skipped, so the record and the stack trace start at the
+	// panicking function.
+	pcs := skipRuntime(callers(1))
+	exit(logger, pcs[0], LevelFatal, "panic", []slog.Attr{slog.Any("panic", v), stackAttr(pcs)})
+}
+
+// exit implements Fatal, Exit and Recover. The source code position
+// of the logged record is pc.
+func exit(logger *slog.Logger, pc uintptr, level slog.Level, msg string, attrs []slog.Attr) {
+	logPC(logger, pc, level, msg, attrs)
+	flushHandler(logger.Handler())
+	osExit(exitCode(level))
+}
+
+// exitCode returns the exit code of level according to the exit
+// codes set by SetExitCode.
+func exitCode(level slog.Level) int {
+	exitCodes.RLock()
+	defer exitCodes.RUnlock()
+
+	code, found := 1, false
+	var best slog.Level
+	for l, c := range exitCodes.m {
+		if l <= level && (!found || l > best) {
+			code, best, found = c, l, true
+		}
+	}
+	return code
+}
+
+// handlerWrapper is implemented by the handlers of this package that
+// wrap other handlers, so the buffered handlers wrapped by them can be
+// flushed.
+type handlerWrapper interface {
+	wrapped() []slog.Handler
+}
+
+// flushHandler flushes h if it has a Flush method, and then the
+// handlers wrapped by it, so the records flushed by h are also
+// flushed by the handlers it wraps.
+func flushHandler(h slog.Handler) {
+	switch f := h.(type) {
+	case interface{ Flush() }:
+		f.Flush()
+	case interface{ Flush() error }:
+		f.Flush() //nolint:errcheck
+	}
+	if w, ok := h.(handlerWrapper); ok {
+		for _, h := range w.wrapped() {
+			flushHandler(h)
+		}
+	}
+}
+
+// argsToAttrs converts args to attributes as done by
+// slog.Logger.Log.
+func argsToAttrs(args []any) []slog.Attr {
+	if len(args) == 0 {
+		return nil
+	}
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
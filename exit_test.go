@@ -0,0 +1,141 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+)
+
+// fakeExit replaces osExit during the test and returns a pointer to
+// the exit code passed to it, which is -1 if it is not called.
+func fakeExit(t *testing.T) *int {
+	code := -1
+	prev := osExit
+	osExit = func(c int) { code = c }
+	t.Cleanup(func() { osExit = prev })
+	return &code
+}
+
+func TestFatal(t *testing.T) {
+	code := fakeExit(t)
+
+	var buf bytes.Buffer
+	h := NewAsyncHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), 0)
+	defer h.Close()
+	Fatal(slog.New(h), "cannot continue", "path", "/tmp")
+
+	if *code != 1 {
+		t.Errorf("unexpected exit code: %v", *code)
+	}
+	// The async handler must have been flushed.
+	if got, want := buf.String(), "FATAL cannot continue path=/tmp\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestFatal_wrapped(t *testing.T) {
+	code := fakeExit(t)
+
+	var buf, file bytes.Buffer
+	async := NewAsyncHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), 16)
+	defer async.Close()
+	tee := MultiHandler(async, slog.NewJSONHandler(&file, nil))
+	h := Chain(NewExitTracker(tee, nil), Filter(func(context.Context, slog.Record) bool { return true }))
+	Fatal(slog.New(h), "cannot continue")
+
+	if *code != 1 {
+		t.Errorf("unexpected exit code: %v", *code)
+	}
+	// The async handler within the tee must have been flushed.
+	if got, want := buf.String(), "FATAL cannot continue\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestExit(t *testing.T) {
+	defer func(codes map[slog.Level]int) {
+		exitCodes.Lock()
+		exitCodes.m = codes
+		exitCodes.Unlock()
+	}(maps.Clone(exitCodes.m))
+	SetExitCode(slog.LevelWarn, 3)
+	SetExitCode(slog.LevelError, 2)
+
+	tests := []struct {
+		level slog.Level
+		want  int
+	}{
+		{slog.LevelInfo, 1},
+		{slog.LevelWarn, 3},
+		{slog.LevelWarn + 1, 3},
+		{slog.LevelError, 2},
+		{LevelFatal, 1},
+		{LevelFatal + 4, 1},
+	}
+
+	for _, tt := range tests {
+		code := fakeExit(t)
+		var buf bytes.Buffer
+		Exit(slog.New(NewCLIHandler(&buf, nil)), tt.level, "message")
+		if *code != tt.want {
+			t.Errorf("unexpected exit code for %v: got: %v, want: %v", tt.level, *code, tt.want)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	code := fakeExit(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}))
+	func() {
+		defer Recover(logger)
+		panic("boom")
+	}()
+
+	if *code != 1 {
+		t.Errorf("unexpected exit code: %v", *code)
+	}
+	got := buf.String()
+	if !strings.HasPrefix(got, "FATAL panic panic=boom\n  stack:\n") {
+		t.Errorf("unexpected output: %q", got)
+	}
+	if !strings.Contains(got, "TestRecover") {
+		t.Errorf("stack trace does not contain the panicking function: %q", got)
+	}
+}
+
+func TestRecover_source(t *testing.T) {
+	fakeExit(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, AddSource: true, SourceFormat: SourceShort}))
+	func() {
+		defer Recover(logger)
+		panic("boom")
+	}()
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "FATAL exit_test.go:") {
+		t.Errorf("unexpected source: %q", got)
+	}
+	if _, stack, _ := strings.Cut(got, "stack:\n"); !strings.HasPrefix(strings.TrimSpace(stack), "github.com/jroimartin/clilog.TestRecover_source") {
+		t.Errorf("unexpected stack trace: %q", stack)
+	}
+}
+
+func TestRecover_noPanic(t *testing.T) {
+	code := fakeExit(t)
+
+	var buf bytes.Buffer
+	func() {
+		defer Recover(slog.New(NewCLIHandler(&buf, nil)))
+	}()
+
+	if *code != -1 || buf.Len() != 0 {
+		t.Errorf("unexpected exit: code: %v, output: %q", *code, buf.String())
+	}
+}
@@ -0,0 +1,208 @@
+package clilog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Defaults of [FileWriterOptions].
+const (
+	DefaultMaxFileSize = 10 << 20
+	DefaultMaxBackups  = 5
+)
+
+// FileWriterOptions are options for a [FileWriter]. A zero
+// FileWriterOptions consists entirely of default values.
+type FileWriterOptions struct {
+	// MaxSize is the size in bytes from which the file is
+	// rotated. If MaxSize is not positive, the writer uses
+	// DefaultMaxFileSize.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files kept. If
+	// MaxBackups is not positive, the writer uses
+	// DefaultMaxBackups.
+	MaxBackups int
+
+	// Compress causes the writer to compress the rotated files
+	// with gzip.
+	Compress bool
+}
+
+// FileWriter is an [io.Writer] that appends to a file and rotates it
+// when it grows beyond a maximum size, so it can be passed as the
+// writer of a handler to persist the logs of a program. Rotated files
+// are named after the file with a numeric suffix, which is 1 for the
+// most recent one (e.g. "tool.log.1" or, if they are compressed,
+// "tool.log.1.gz"). FileWriter is safe for concurrent use.
+type FileWriter struct {
+	path string
+	opts FileWriterOptions
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewFileWriter returns a new [FileWriter] that appends to the file
+// at path, creating it and its parent directories if needed. If opts
+// is nil, the default options are used.
+func NewFileWriter(path string, opts *FileWriterOptions) (*FileWriter, error) {
+	if opts == nil {
+		opts = &FileWriterOptions{}
+	}
+	w := &FileWriter{path: path, opts: *opts}
+	if w.opts.MaxSize <= 0 {
+		w.opts.MaxSize = DefaultMaxFileSize
+	}
+	if w.opts.MaxBackups <= 0 {
+		w.opts.MaxBackups = DefaultMaxBackups
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p to the file. If the file would grow beyond the
+// maximum size, it is rotated first. A single write larger than the
+// maximum size is written to an empty file.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file, regardless of its size.
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the file.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+// open opens the file for appending.
+func (w *FileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size = f, fi.Size()
+	return nil
+}
+
+// rotate closes the file, shifts the backups, discarding the oldest
+// one, and opens a new file. w.mu must be held.
+func (w *FileWriter) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+
+	var errs []error
+	for _, ext := range []string{"", ".gz"} {
+		if err := os.Remove(w.backup(w.opts.MaxBackups) + ext); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		for i := w.opts.MaxBackups - 1; i > 0; i-- {
+			if err := os.Rename(w.backup(i)+ext, w.backup(i+1)+ext); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := os.Rename(w.path, w.backup(1)); err != nil {
+		errs = append(errs, err)
+	} else if w.opts.Compress {
+		if err := compressFile(w.backup(1)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := w.open(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("could not rotate %v: %w", w.path, err)
+	}
+	return nil
+}
+
+// backup returns the path of the backup with index i.
+func (w *FileWriter) backup(i int) string {
+	return w.path + "." + strconv.Itoa(i)
+}
+
+// compressFile compresses the file at path with gzip, replacing it
+// with path.gz.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + ".gz")
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,125 @@
+package clilog
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "tool.log")
+
+	w, err := NewFileWriter(path, &FileWriterOptions{MaxSize: 20, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	defer w.Close()
+
+	logger := slog.New(NewCLIHandler(w, &HandlerOptions{OmitTime: true}))
+	for _, msg := range []string{"first", "second", "third", "fourth"} {
+		logger.Info(msg)
+	}
+
+	files := map[string]string{
+		path:        "INFO fourth\n",
+		path + ".1": "INFO third\n",
+		path + ".2": "INFO second\n",
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("could not read file: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("unexpected contents of %v: got: %q, want: %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("unexpected backup: %v", err)
+	}
+}
+
+func TestFileWriter_append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.log")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+
+	w, err := NewFileWriter(path, &FileWriterOptions{MaxSize: 15})
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("abcd")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if _, err := w.Write([]byte("efgh")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	if got, _ := os.ReadFile(path + ".1"); string(got) != "0123456789abcd" {
+		t.Errorf("unexpected backup contents: %q", got)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "efgh" {
+		t.Errorf("unexpected file contents: %q", got)
+	}
+}
+
+func TestFileWriter_Compress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.log")
+
+	w, err := NewFileWriter(path, &FileWriterOptions{Compress: true})
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	defer w.Close()
+
+	for _, s := range []string{"first\n", "second\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		if err := w.Rotate(); err != nil {
+			t.Fatalf("rotate error: %v", err)
+		}
+	}
+
+	for i, want := range []string{"second\n", "first\n"} {
+		name := w.backup(i+1) + ".gz"
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("could not open backup: %v", err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("could not read backup: %v", err)
+		}
+		got, err := io.ReadAll(zr)
+		f.Close()
+		if err != nil {
+			t.Fatalf("could not read backup: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("unexpected contents of %v: got: %q, want: %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(w.backup(1)); !os.IsNotExist(err) {
+		t.Errorf("uncompressed backup not removed: %v", err)
+	}
+}
+
+func TestFileWriter_Close(t *testing.T) {
+	w, err := NewFileWriter(filepath.Join(t.TempDir(), "tool.log"), nil)
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	if _, err := w.Write([]byte("x")); err != os.ErrClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
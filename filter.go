@@ -0,0 +1,54 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// FilterHandler returns a [slog.Handler] that passes to h the records
+// for which keep returns true and drops the rest. keep can inspect the
+// level, message, attributes and program counter of the records, so
+// they can be filtered by message pattern, attribute value or source
+// package. The attributes added with WithAttrs are not part of the
+// records passed to keep.
+func FilterHandler(h slog.Handler, keep func(ctx context.Context, r slog.Record) bool) slog.Handler {
+	return &filterHandler{h: h, keep: keep}
+}
+
+// filterHandler is the [slog.Handler] returned by FilterHandler.
+type filterHandler struct {
+	h    slog.Handler
+	keep func(ctx context.Context, r slog.Record) bool
+}
+
+// Enabled reports whether the underlying handler handles records at
+// the given level.
+func (h *filterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the underlying handler if keep returns
+// true.
+func (h *filterHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.keep(ctx, r) {
+		return nil
+	}
+	return h.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both
+// the receiver's attributes and the arguments.
+func (h *filterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &filterHandler{h: h.h.WithAttrs(attrs), keep: h.keep}
+}
+
+// WithGroup returns a new Handler with the given group appended to
+// the receiver's existing groups.
+func (h *filterHandler) WithGroup(name string) slog.Handler {
+	return &filterHandler{h: h.h.WithGroup(name), keep: h.keep}
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *filterHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
@@ -0,0 +1,36 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFilterHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	keep := func(ctx context.Context, r slog.Record) bool {
+		if strings.HasPrefix(r.Message, "health") {
+			return false
+		}
+		drop := false
+		r.Attrs(func(a slog.Attr) bool {
+			drop = a.Key == "path" && a.Value.String() == "/metrics"
+			return !drop
+		})
+		return !drop
+	}
+	h := FilterHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), keep)
+	logger := slog.New(h).With("svc", "api").WithGroup("req")
+
+	logger.Info("health check")
+	logger.Info("request", "path", "/metrics")
+	logger.Info("request", "path", "/users")
+
+	want := "INFO request svc=api req.path=/users\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// LevelFlag is a command line flag that sets the level of a
+// [slog.LevelVar]. It implements [flag.Value] and
+// [encoding.TextUnmarshaler], so it can be used with the flag package
+// and with configuration decoders. Levels are parsed by [ParseLevel].
+//
+// For instance:
+//
+//	var level slog.LevelVar
+//	flag.Var(clilog.NewLevelFlag(&level), "log-level", "log `level` (trace, debug, info, warn, error)")
+//	flag.Parse()
+//	logger := clilog.New(os.Stderr, &clilog.HandlerOptions{Level: &level})
+type LevelFlag struct {
+	lv *slog.LevelVar
+}
+
+// NewLevelFlag returns a new [LevelFlag] that sets lv. If lv is nil, a
+// new [slog.LevelVar] is allocated. Its value is the default value of
+// the flag.
+func NewLevelFlag(lv *slog.LevelVar) *LevelFlag {
+	if lv == nil {
+		lv = &slog.LevelVar{}
+	}
+	return &LevelFlag{lv: lv}
+}
+
+// Level returns the current level. It implements [slog.Leveler], so
+// the flag can be passed as the level of a handler.
+func (f *LevelFlag) Level() slog.Level {
+	if f.lv == nil {
+		return slog.LevelInfo
+	}
+	return f.lv.Level()
+}
+
+// String returns the name of the current level, as returned by
+// [LevelString].
+func (f *LevelFlag) String() string {
+	if f == nil || f.lv == nil {
+		return ""
+	}
+	return LevelString(f.lv.Level())
+}
+
+// Set sets the level parsed from s.
+func (f *LevelFlag) Set(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	if f.lv == nil {
+		f.lv = &slog.LevelVar{}
+	}
+	f.lv.Set(level)
+	return nil
+}
+
+// Get returns the current level. It implements [flag.Getter].
+func (f *LevelFlag) Get() any {
+	return f.Level()
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (f *LevelFlag) MarshalText() ([]byte, error) {
+	return []byte(LevelString(f.Level())), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (f *LevelFlag) UnmarshalText(data []byte) error {
+	return f.Set(string(data))
+}
+
+// VerbosityLevel returns the level enabled by the provided verbosity,
+// typically the number of times a -v flag is passed. Verbosity 0 is
+// LevelInfo, and every increment lowers the level by 4, so 1 is
+// LevelDebug, 2 is LevelTrace, 3 is "TRACE-4" and so on. Negative
+// verbosities raise the level (e.g. -1 is LevelWarn).
+func VerbosityLevel(verbosity int) slog.Level {
+	return slog.LevelInfo - slog.Level(4*verbosity)
+}
+
+// Verbosity is a repeatable command line flag that counts its
+// occurrences and sets the level of a [slog.LevelVar] according to
+// [VerbosityLevel]. It implements [flag.Value] as a boolean flag, so
+// it does not take a value. Passing an explicit count (e.g. -v=3)
+// sets the verbosity.
+//
+// For instance, the following code enables debug records with -v and
+// trace records with -v -v:
+//
+//	var level slog.LevelVar
+//	flag.Var(clilog.NewVerbosity(&level), "v", "increase verbosity (repeatable)")
+//	flag.Parse()
+//	logger := clilog.New(os.Stderr, &clilog.HandlerOptions{Level: &level})
+type Verbosity struct {
+	n  int
+	lv *slog.LevelVar
+}
+
+// NewVerbosity returns a new [Verbosity] that sets lv. If lv is nil,
+// a new [slog.LevelVar] is allocated. The verbosity starts at 0, so lv
+// is set to LevelInfo.
+func NewVerbosity(lv *slog.LevelVar) *Verbosity {
+	if lv == nil {
+		lv = &slog.LevelVar{}
+	}
+	lv.Set(VerbosityLevel(0))
+	return &Verbosity{lv: lv}
+}
+
+// Count returns the current verbosity.
+func (v *Verbosity) Count() int {
+	return v.n
+}
+
+// Level returns the level enabled by the current verbosity. It
+// implements [slog.Leveler], so the flag can be passed as the level
+// of a handler.
+func (v *Verbosity) Level() slog.Level {
+	return VerbosityLevel(v.n)
+}
+
+// String returns the current verbosity.
+func (v *Verbosity) String() string {
+	if v == nil {
+		return "0"
+	}
+	return strconv.Itoa(v.n)
+}
+
+// Set increments the verbosity if s is "true", resets it if s is
+// "false" and sets it if s is a number.
+func (v *Verbosity) Set(s string) error {
+	switch s {
+	case "true":
+		v.n++
+	case "false":
+		v.n = 0
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid verbosity %q: must be a number", s)
+		}
+		v.n = n
+	}
+	if v.lv != nil {
+		v.lv.Set(v.Level())
+	}
+	return nil
+}
+
+// IsBoolFlag reports that the flag does not take a value.
+func (v *Verbosity) IsBoolFlag() bool {
+	return true
+}
+
+// Get returns the current verbosity. It implements [flag.Getter].
+func (v *Verbosity) Get() any {
+	return v.n
+}
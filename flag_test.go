@@ -0,0 +1,175 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    slog.Level
+		wantErr string
+	}{
+		{s: "debug", want: slog.LevelDebug},
+		{s: "INFO", want: slog.LevelInfo},
+		{s: "Warning", want: slog.LevelWarn},
+		{s: "trace-2", want: LevelTrace - 2},
+		{s: "notice+1", want: LevelNotice + 1},
+		{s: "ERROR+2", want: slog.LevelError + 2},
+		{s: "fatal", want: LevelFatal},
+		{s: "-4", want: slog.LevelDebug},
+		{s: "3", want: slog.Level(3)},
+		{s: "verbose", wantErr: `unknown level "verbose"`},
+		{s: "", wantErr: `unknown level ""`},
+		{s: "info+x", wantErr: `invalid level offset in "info+x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseLevel(tt.s)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("unexpected error: got: %v, want: %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected level: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLevel_roundTrip(t *testing.T) {
+	for l := LevelTrace - 2; l <= LevelFatal+2; l++ {
+		got, err := ParseLevel(LevelString(l))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != l {
+			t.Errorf("unexpected level: got: %v, want: %v", got, l)
+		}
+	}
+}
+
+func TestLevelFlag(t *testing.T) {
+	var lv slog.LevelVar
+	lv.Set(slog.LevelWarn)
+
+	var out bytes.Buffer
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&out)
+	fs.Var(NewLevelFlag(&lv), "log-level", "log level")
+
+	fs.PrintDefaults()
+	if want := `(default WARN)`; !strings.Contains(out.String(), want) {
+		t.Errorf("default value not found in %q", out.String())
+	}
+
+	if err := fs.Parse([]string{"-log-level", "debug+1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := lv.Level(), slog.LevelDebug+1; got != want {
+		t.Errorf("unexpected level: got: %v, want: %v", got, want)
+	}
+
+	if err := fs.Parse([]string{"-log-level", "loud"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestLevelFlag_UnmarshalText(t *testing.T) {
+	f := NewLevelFlag(nil)
+	if err := f.UnmarshalText([]byte("Error")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.Level(); got != slog.LevelError {
+		t.Errorf("unexpected level: got: %v, want: %v", got, slog.LevelError)
+	}
+	text, err := f.MarshalText()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(text); got != "ERROR" {
+		t.Errorf("unexpected text: got: %v, want: ERROR", got)
+	}
+}
+
+func TestVerbosityLevel(t *testing.T) {
+	tests := []struct {
+		verbosity int
+		want      string
+	}{
+		{-2, "ERROR"},
+		{-1, "WARN"},
+		{0, "INFO"},
+		{1, "DEBUG"},
+		{2, "TRACE"},
+		{3, "TRACE-4"},
+	}
+
+	for _, tt := range tests {
+		if got := LevelString(VerbosityLevel(tt.verbosity)); got != tt.want {
+			t.Errorf("unexpected level for verbosity %v: got: %v, want: %v", tt.verbosity, got, tt.want)
+		}
+	}
+}
+
+func TestVerbosity(t *testing.T) {
+	tests := []struct {
+		args []string
+		want slog.Level
+	}{
+		{nil, slog.LevelInfo},
+		{[]string{"-v"}, slog.LevelDebug},
+		{[]string{"-v", "-v"}, LevelTrace},
+		{[]string{"-v", "-v", "-v"}, LevelTrace - 4},
+		{[]string{"-v=2"}, LevelTrace},
+		{[]string{"-v", "-v=false"}, slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var lv slog.LevelVar
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			v := NewVerbosity(&lv)
+			fs.Var(v, "v", "verbosity")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := lv.Level(); got != tt.want {
+				t.Errorf("unexpected level: got: %v, want: %v", got, tt.want)
+			}
+			if got := v.Level(); got != tt.want {
+				t.Errorf("unexpected flag level: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerbosity_handler(t *testing.T) {
+	var buf bytes.Buffer
+
+	v := NewVerbosity(nil)
+	if err := v.Set("3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger := New(&buf, &HandlerOptions{OmitTime: true, Level: v})
+	logger.Log(context.Background(), VerbosityLevel(3), "very verbose")
+	logger.Log(context.Background(), VerbosityLevel(4), "too verbose")
+
+	want := "TRACE-4 very verbose\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+package clilog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"unicode/utf8"
+)
+
+// OutputFormat controls the format of the lines written by a
+// [CLIHandler].
+type OutputFormat int
+
+// Output formats.
+const (
+	// FormatText renders human readable lines.
+	FormatText OutputFormat = iota
+
+	// FormatLogfmt renders lines that comply with logfmt, so they
+	// can be parsed by logfmt libraries. The built-in attributes
+	// are rendered with the keys "time", "level", "source" and
+	// "msg", keys are sanitized and values are quoted when needed.
+	// Colors, icons, layouts and multi-line blocks are disabled.
+	FormatLogfmt
+
+	// FormatAuto selects FormatText if the writer is a terminal,
+	// FormatJournald if it is connected to the systemd journal
+	// and FormatLogfmt otherwise (e.g. when the output is
+	// redirected to a file or a pipe). The handlers created with
+	// NewCLIHandlerSplit select the format according to stdout.
+	FormatAuto
+
+	// FormatGitHub renders the records with level NOTICE or
+	// higher as GitHub Actions workflow commands (e.g.
+	// "::warning file=main.go,line=12::message"), so they are
+	// shown as annotations in workflow runs and pull requests.
+	// The source code position is used as the location of the
+	// annotation when AddSource is set. The rest of records are
+	// rendered as with FormatText. See also
+	// CLIHandler.StartGroup.
+	FormatGitHub
+
+	// FormatGitLab renders the records as with FormatText. The
+	// groups started with CLIHandler.StartGroup are rendered as
+	// GitLab CI collapsible sections.
+	FormatGitLab
+
+	// FormatAzure renders the records with level WARN or higher
+	// as Azure Pipelines logging commands (e.g.
+	// "##vso[task.logissue type=warning;]message"). The source
+	// code position is used as the location of the issue when
+	// AddSource is set. The rest of records are rendered as with
+	// FormatText. See also CLIHandler.StartGroup.
+	FormatAzure
+
+	// FormatCI selects FormatGitHub, FormatGitLab or FormatAzure
+	// depending on the CI system detected from the environment
+	// (GITHUB_ACTIONS, GITLAB_CI and TF_BUILD). If none is
+	// detected, FormatText is selected.
+	FormatCI
+
+	// FormatJournald renders single lines prefixed with the
+	// syslog priority matching the level of the record (e.g.
+	// "<4>message"), as expected by systemd-journald for the
+	// output of services. The time and the level are omitted,
+	// because the journal stores them, and colors and multi-line
+	// blocks are disabled. Unless Template is set, the lines are
+	// rendered with the template "{source} {msg}{attrs}".
+	FormatJournald
+)
+
+// String returns a name for the output format.
+func (f OutputFormat) String() string {
+	switch f {
+	case FormatText:
+		return "text"
+	case FormatLogfmt:
+		return "logfmt"
+	case FormatAuto:
+		return "auto"
+	case FormatGitHub:
+		return "github"
+	case FormatGitLab:
+		return "gitlab"
+	case FormatAzure:
+		return "azure"
+	case FormatCI:
+		return "ci"
+	case FormatJournald:
+		return "journald"
+	default:
+		return fmt.Sprintf("OutputFormat(%d)", int(f))
+	}
+}
+
+// resolveFormat returns the output format used to write to w.
+func resolveFormat(w io.Writer, format OutputFormat) OutputFormat {
+	switch format {
+	case FormatAuto:
+		if isTerminal(w) {
+			return FormatText
+		}
+		if isJournal(w) {
+			return FormatJournald
+		}
+		return FormatLogfmt
+	case FormatCI:
+		return detectCIFormat()
+	default:
+		return format
+	}
+}
+
+// logfmtOptions returns a copy of opts with the options incompatible
+// with logfmt disabled.
+func logfmtOptions(opts HandlerOptions) HandlerOptions {
+	opts.Color = ColorNever
+	if opts.Quote == QuoteNever {
+		opts.Quote = QuoteWhenNeeded
+	}
+	opts.NoEscape = false
+	opts.KVSeparator = "="
+	opts.AttrSeparator = " "
+	opts.Multiline = false
+	opts.ErrorChain = false
+	opts.Icons = IconsOff
+	opts.LevelWidth = 0
+	opts.SourceLinks = false
+	opts.Layout = TimeFirst
+	opts.Overflow = OverflowNone
+	opts.GroupStyle = GroupsDotted
+	opts.Expanded = false
+	opts.Template = ""
+	opts.DigitSeparator = ""
+	return opts
+}
+
+// appendLogfmtHeader appends to b the built-in attributes of r in
+// logfmt format.
+func (h *CLIHandler) appendLogfmtHeader(b *buffer, out *output, r slog.Record) {
+	h.appendLogfmtField(b, slog.TimeKey, func() bool {
+		return h.appendTime(b, out, r)
+	})
+	h.appendLogfmtField(b, slog.LevelKey, func() bool {
+		return h.appendLevel(b, out, r)
+	})
+	h.appendLogfmtField(b, slog.SourceKey, func() bool {
+		return h.appendSource(b, out, r)
+	})
+	if v, ok := h.builtin(slog.MessageKey, slog.StringValue(r.Message)); ok {
+		if len(*b) > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(slog.MessageKey + "=")
+		h.appendString(b, v.String())
+	}
+}
+
+// appendLogfmtField appends to b the built-in attribute with the
+// provided key. Its value is appended by appendFn, which reports
+// whether the attribute must be rendered, and is quoted if needed.
+func (h *CLIHandler) appendLogfmtField(b *buffer, key string, appendFn func() bool) {
+	mark := len(*b)
+	if mark > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	start := len(*b)
+	if !appendFn() {
+		*b = (*b)[:mark]
+		return
+	}
+	val := (*b)[start:]
+	if h.opts.Quote == QuoteAlways || needsQuotingBytes(val) {
+		*b = strconv.AppendQuote((*b)[:start], string(val))
+	}
+}
+
+// appendLogfmtKey appends to b the key qualified by groups, replacing
+// the characters not allowed in logfmt keys with underscores.
+func appendLogfmtKey(b *buffer, groups []string, key string) {
+	for _, g := range groups {
+		appendLogfmtIdent(b, g)
+		b.WriteByte('.')
+	}
+	appendLogfmtIdent(b, key)
+}
+
+// appendLogfmtIdent appends to b the string s, replacing the
+// characters not allowed in logfmt keys with underscores.
+func appendLogfmtIdent(b *buffer, s string) {
+	if s == "" {
+		b.WriteByte('_')
+		return
+	}
+	for _, r := range s {
+		if needsQuotingRune(r) {
+			b.WriteByte('_')
+			continue
+		}
+		*b = utf8.AppendRune(*b, r)
+	}
+}
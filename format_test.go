@@ -0,0 +1,132 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestCLIHandler_logfmt(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *HandlerOptions
+		with  func(*slog.Logger) *slog.Logger
+		msg   string
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name:  "attrs",
+			attrs: []slog.Attr{slog.String("c", "foo"), slog.Int("n", 1)},
+			want:  `time=2023-09-20T12:24:43Z level=INFO msg=message c=foo n=1`,
+		},
+		{
+			name:  "quoted",
+			msg:   "hello world",
+			attrs: []slog.Attr{slog.String("c", "a=b"), slog.String("e", "")},
+			want:  `time=2023-09-20T12:24:43Z level=INFO msg="hello world" c="a=b" e=""`,
+		},
+		{
+			name: "groups",
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("w", 1).WithGroup("g")
+			},
+			attrs: []slog.Attr{slog.Group("h", slog.Int("a", 1))},
+			want:  `time=2023-09-20T12:24:43Z level=INFO msg=message w=1 g.h.a=1`,
+		},
+		{
+			name:  "keys",
+			attrs: []slog.Attr{slog.String("a b", "1"), slog.String("c=\"d\"\n", "2")},
+			want:  `time=2023-09-20T12:24:43Z level=INFO msg=message a_b=1 c__d__=2`,
+		},
+		{
+			name:  "TimeFormat",
+			opts:  &HandlerOptions{Format: FormatLogfmt, TimeFormat: "2006-01-02 15:04:05"},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `time="2023-09-20 12:24:43" level=INFO msg=message c=foo`,
+		},
+		{
+			name:  "OmitTime",
+			opts:  &HandlerOptions{Format: FormatLogfmt, OmitTime: true},
+			attrs: []slog.Attr{slog.String("c", "foo")},
+			want:  `level=INFO msg=message c=foo`,
+		},
+		{
+			name:  "Quote never",
+			opts:  &HandlerOptions{Format: FormatLogfmt, OmitTime: true, Quote: QuoteNever},
+			msg:   "hello\nworld",
+			attrs: []slog.Attr{slog.String("c", "foo bar")},
+			want:  `level=INFO msg="hello\nworld" c="foo bar"`,
+		},
+		{
+			name: "human readable options",
+			opts: &HandlerOptions{
+				Format:        FormatLogfmt,
+				OmitTime:      true,
+				Color:         ColorAlways,
+				Icons:         IconsPrefix,
+				LevelWidth:    8,
+				KVSeparator:   ": ",
+				AttrSeparator: "\t",
+				Multiline:     true,
+				ErrorChain:    true,
+				Layout:        MessageFirst,
+			},
+			attrs: []slog.Attr{
+				slog.String("out", "line 1\nline 2"),
+				slog.Any("err", fmt.Errorf("wrap: %w", errors.New("cause"))),
+			},
+			want: `level=INFO msg=message out="line 1\nline 2" err="wrap: cause"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if opts == nil {
+				opts = &HandlerOptions{Format: FormatLogfmt}
+			}
+			msg := tt.msg
+			if msg == "" {
+				msg = "message"
+			}
+
+			var buf bytes.Buffer
+			logger := slog.New(setTimeHandler{testTime, NewCLIHandler(&buf, opts)})
+			if tt.with != nil {
+				logger = tt.with(logger)
+			}
+			logger.LogAttrs(context.Background(), slog.LevelInfo, msg, tt.attrs...)
+
+			if got, want := buf.String(), tt.want+"\n"; got != want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_logfmt_stack(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{Format: FormatLogfmt}))
+	logger.Info("message", Stack())
+
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 1 {
+		t.Errorf("stack trace not rendered inline: %q", buf.String())
+	}
+}
+
+func TestCLIHandler_FormatAuto(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{Format: FormatAuto, OmitTime: true})
+	if h.opts.Format != FormatLogfmt {
+		t.Errorf("unexpected format: got: %v, want: %v", h.opts.Format, FormatLogfmt)
+	}
+
+	slog.New(h).Info("hello world", "c", "foo")
+	if got, want := buf.String(), "level=INFO msg=\"hello world\" c=foo\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
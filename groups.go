@@ -0,0 +1,64 @@
+package clilog
+
+import "fmt"
+
+// GroupStyle controls how a [CLIHandler] renders the attributes
+// within groups.
+type GroupStyle int
+
+// Group styles.
+const (
+	// GroupsDotted qualifies the keys of the attributes with the
+	// names of their groups (e.g. "g.a=1 g.d=4").
+	GroupsDotted GroupStyle = iota
+
+	// GroupsBracketed renders groups as lists of attributes
+	// between brackets (e.g. "g=[a=1 d=4]"), so the names of the
+	// groups are not repeated.
+	GroupsBracketed
+)
+
+// String returns a name for the group style.
+func (s GroupStyle) String() string {
+	switch s {
+	case GroupsDotted:
+		return "dotted"
+	case GroupsBracketed:
+		return "bracketed"
+	default:
+		return fmt.Sprintf("GroupStyle(%d)", int(s))
+	}
+}
+
+// enterGroups closes and opens the brackets required to render an
+// attribute within groups, when GroupsBracketed is used. In expanded
+// mode, the headers of the groups are appended to the blocks instead.
+// Groups are only opened when an attribute is rendered, so empty
+// groups are omitted.
+func (h *CLIHandler) enterGroups(buf *attrBuffer, groups []string) {
+	n := 0
+	for n < len(buf.open) && n < len(groups) && buf.open[n] == groups[n] {
+		n++
+	}
+	for len(buf.open) > n {
+		if !h.opts.Expanded {
+			buf.line.WriteByte(']')
+			buf.first = false
+		}
+		buf.open = buf.open[:len(buf.open)-1]
+	}
+	for _, g := range groups[n:] {
+		if h.opts.Expanded {
+			appendIndent(buf.blocks, len(buf.open)+1)
+			h.appendEscaped(buf.blocks, g)
+			buf.blocks.WriteString(":\n")
+		} else {
+			h.appendSeparator(buf)
+			h.appendEscaped(buf.line, g)
+			buf.line.WriteString(h.opts.KVSeparator)
+			buf.line.WriteByte('[')
+			buf.first = true
+		}
+		buf.open = append(buf.open, g)
+	}
+}
@@ -0,0 +1,75 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// IconMode controls whether a [CLIHandler] renders level icons.
+type IconMode int
+
+// Icon modes.
+const (
+	// IconsOff disables icons.
+	IconsOff IconMode = iota
+
+	// IconsPrefix renders the icon before the level name.
+	IconsPrefix
+
+	// IconsReplace renders the icon instead of the level name.
+	IconsReplace
+)
+
+// String returns a name for the icon mode.
+func (m IconMode) String() string {
+	switch m {
+	case IconsOff:
+		return "off"
+	case IconsPrefix:
+		return "prefix"
+	case IconsReplace:
+		return "replace"
+	default:
+		return fmt.Sprintf("IconMode(%d)", int(m))
+	}
+}
+
+// IconSet defines the icons used by a [CLIHandler] to represent
+// levels. Levels between named levels use the icon of the closest
+// lower one. If Trace, Notice or Fatal are empty, the icon of the
+// closest standard level is used: Debug for Trace, Info for Notice
+// and Error for Fatal.
+type IconSet struct {
+	Trace  string
+	Debug  string
+	Info   string
+	Notice string
+	Warn   string
+	Error  string
+	Fatal  string
+}
+
+// DefaultIcons is the [IconSet] used when [HandlerOptions.IconSet]
+// is nil.
+var DefaultIcons = IconSet{
+	Trace:  "·",
+	Debug:  "·",
+	Info:   "•",
+	Notice: "✔",
+	Warn:   "!",
+	Error:  "✖",
+	Fatal:  "✖",
+}
+
+// icon returns the icon of the provided level.
+func (s *IconSet) icon(level slog.Level) string {
+	return selectLevel(level, levelValues{
+		trace:  s.Trace,
+		debug:  s.Debug,
+		info:   s.Info,
+		notice: s.Notice,
+		warn:   s.Warn,
+		error:  s.Error,
+		fatal:  s.Fatal,
+	})
+}
@@ -0,0 +1,81 @@
+package clilog
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// journaldOptions returns a copy of opts with the options
+// incompatible with journald disabled.
+func journaldOptions(opts HandlerOptions) HandlerOptions {
+	opts.Color = ColorNever
+	opts.OmitTime = true
+	opts.Multiline = false
+	opts.ErrorChain = false
+	opts.Expanded = false
+	opts.Icons = IconsOff
+	opts.LevelWidth = 0
+	opts.SourceLinks = false
+	opts.Overflow = OverflowNone
+	if opts.Template == "" {
+		opts.Template = "{source} {msg}{attrs}"
+	}
+	return opts
+}
+
+// journalPriority returns the syslog priority matching the provided
+// level.
+func journalPriority(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // err
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= LevelNotice:
+		return 5 // notice
+	case level >= slog.LevelInfo:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
+
+// appendJournalPriority appends to b the sd-daemon prefix with the
+// syslog priority matching the provided level.
+func appendJournalPriority(b *buffer, level slog.Level) {
+	b.WriteByte('<')
+	*b = strconv.AppendInt(*b, int64(journalPriority(level)), 10)
+	b.WriteByte('>')
+}
+
+// isJournal reports whether w is connected to the systemd journal,
+// according to the device and inode numbers in the environment
+// variable JOURNAL_STREAM, which is set by systemd for services
+// whose output is sent to the journal.
+func isJournal(w io.Writer) bool {
+	s := os.Getenv("JOURNAL_STREAM")
+	if s == "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	devStr, inoStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return false
+	}
+	dev, err := strconv.ParseUint(devStr, 10, 64)
+	if err != nil {
+		return false
+	}
+	ino, err := strconv.ParseUint(inoStr, 10, 64)
+	if err != nil {
+		return false
+	}
+	fdev, fino, ok := fileID(f)
+	return ok && fdev == dev && fino == ino
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package clilog
+
+import "os"
+
+// fileID returns the device and inode numbers of f. The systemd
+// journal is only supported on unix systems, so it always returns
+// false.
+func fileID(f *os.File) (dev, ino uint64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,87 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestCLIHandler_journald(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *HandlerOptions
+		log  func(*slog.Logger)
+		want string
+	}{
+		{
+			name: "levels",
+			log: func(l *slog.Logger) {
+				l.Debug("debug")
+				l.Info("info", "n", 1)
+				l.Log(context.Background(), LevelNotice, "notice")
+				l.Warn("warn")
+				l.Error("error", "err", errors.New("failed"))
+			},
+			want: "<7>debug\n<6>info n=1\n<5>notice\n<4>warn\n<3>error err=failed\n",
+		},
+		{
+			name: "colors and multiline",
+			opts: &HandlerOptions{Color: ColorAlways, Multiline: true},
+			log: func(l *slog.Logger) {
+				l.Info("message", "text", "a\nb")
+			},
+			want: "<6>message text=\"a\\nb\"\n",
+		},
+		{
+			name: "Template",
+			opts: &HandlerOptions{Template: "[{level}] {msg}{attrs}"},
+			log: func(l *slog.Logger) {
+				l.With("a", 1).Warn("message")
+			},
+			want: "<4>[WARN] message a=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts HandlerOptions
+			if tt.opts != nil {
+				opts = *tt.opts
+			}
+			opts.Level = slog.LevelDebug
+			opts.Format = FormatJournald
+
+			var buf bytes.Buffer
+			logger := slog.New(NewCLIHandler(&buf, &opts))
+			tt.log(logger)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_journald_stack(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{Format: FormatJournald}))
+	logger.Info("message", Stack())
+
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 1 {
+		t.Errorf("stack trace not rendered inline: %q", buf.String())
+	}
+}
+
+func TestIsJournal(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	if isJournal(&bytes.Buffer{}) {
+		t.Error("buffer detected as journal")
+	}
+
+	t.Setenv("JOURNAL_STREAM", "1:2")
+	if isJournal(&bytes.Buffer{}) {
+		t.Error("buffer detected as journal")
+	}
+}
@@ -0,0 +1,39 @@
+//go:build unix
+
+package clilog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestIsJournal_file(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "journal"))
+	if err != nil {
+		t.Fatalf("could not create file: %v", err)
+	}
+	defer f.Close()
+
+	dev, ino, ok := fileID(f)
+	if !ok {
+		t.Fatal("could not get file ID")
+	}
+
+	t.Setenv("JOURNAL_STREAM", strconv.FormatUint(dev, 10)+":"+strconv.FormatUint(ino, 10))
+	if !isJournal(f) {
+		t.Error("file not detected as journal")
+	}
+	if h := NewCLIHandler(f, &HandlerOptions{Format: FormatAuto}); h.opts.Format != FormatJournald {
+		t.Errorf("unexpected format: got: %v, want: %v", h.opts.Format, FormatJournald)
+	}
+	if h := NewAutoHandler(f); h.opts.Format != FormatJournald {
+		t.Errorf("unexpected auto format: got: %v, want: %v", h.opts.Format, FormatJournald)
+	}
+
+	t.Setenv("JOURNAL_STREAM", strconv.FormatUint(dev, 10)+":"+strconv.FormatUint(ino+1, 10))
+	if isJournal(f) {
+		t.Error("file detected as journal")
+	}
+}
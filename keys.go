@@ -0,0 +1,93 @@
+package clilog
+
+import (
+	"path"
+	"strings"
+)
+
+// redacted replaces the values of the attributes matching
+// [HandlerOptions.RedactKeys].
+const redacted = "[REDACTED]"
+
+// matchKey reports whether any of the provided glob patterns matches
+// either key or its group-qualified form (e.g. "http.headers.key").
+// Patterns are matched case-insensitively using the syntax of
+// [path.Match].
+func matchKey(patterns []string, groups []string, key string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	qualified := key
+	if len(groups) > 0 {
+		qualified = strings.ToLower(strings.Join(groups, ".")) + "." + key
+	}
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, qualified); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dropKey reports whether the attribute with the provided key and
+// groups must be discarded according to [HandlerOptions.DropKeys] and
+// [HandlerOptions.OnlyKeys]. Groups are never discarded because of
+// OnlyKeys, since some of their attributes could be allowed.
+func (h *CLIHandler) dropKey(groups []string, key string, group bool) bool {
+	if matchKey(h.opts.DropKeys, groups, key) {
+		return true
+	}
+	if len(h.opts.OnlyKeys) == 0 || group {
+		return false
+	}
+	// The attributes within an allowed group are allowed.
+	for i, g := range groups {
+		if matchKey(h.opts.OnlyKeys, groups[:i], g) {
+			return false
+		}
+	}
+	return !matchKey(h.opts.OnlyKeys, groups, key)
+}
+
+// displayKey returns the groups and key used to display the attribute
+// with the provided key and groups according to
+// [HandlerOptions.RenameKeys] and [HandlerOptions.StripPrefixes].
+func (h *CLIHandler) displayKey(groups []string, key string) ([]string, string) {
+	if len(h.opts.RenameKeys) > 0 {
+		if len(groups) > 0 {
+			if name, ok := h.opts.RenameKeys[strings.Join(groups, ".")+"."+key]; ok {
+				return nil, name
+			}
+		}
+		if name, ok := h.opts.RenameKeys[key]; ok {
+			key = name
+		}
+	}
+	for _, p := range h.opts.StripPrefixes {
+		if n, ok := groupPrefix(groups, p); ok {
+			return groups[n:], key
+		}
+	}
+	return groups, key
+}
+
+// groupPrefix reports whether prefix, a dot-separated list of group
+// names, is a prefix of groups. It also returns the number of groups
+// in prefix.
+func groupPrefix(groups []string, prefix string) (int, bool) {
+	n := 0
+	for prefix != "" {
+		name, rest, _ := strings.Cut(prefix, ".")
+		if n >= len(groups) || groups[n] != name {
+			return 0, false
+		}
+		prefix = rest
+		n++
+	}
+	return n, n > 0
+}
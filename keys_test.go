@@ -0,0 +1,73 @@
+package clilog
+
+import "testing"
+
+func TestMatchKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		groups   []string
+		key      string
+		want     bool
+	}{
+		{
+			name:     "no patterns",
+			patterns: nil,
+			key:      "password",
+			want:     false,
+		},
+		{
+			name:     "exact",
+			patterns: []string{"password"},
+			key:      "password",
+			want:     true,
+		},
+		{
+			name:     "case insensitive",
+			patterns: []string{"authorization"},
+			key:      "Authorization",
+			want:     true,
+		},
+		{
+			name:     "glob",
+			patterns: []string{"*_token"},
+			key:      "github_token",
+			want:     true,
+		},
+		{
+			name:     "glob no match",
+			patterns: []string{"*_token"},
+			key:      "tokens",
+			want:     false,
+		},
+		{
+			name:     "bare key in group",
+			patterns: []string{"password"},
+			groups:   []string{"db", "conn"},
+			key:      "password",
+			want:     true,
+		},
+		{
+			name:     "qualified",
+			patterns: []string{"http.headers.*"},
+			groups:   []string{"http", "headers"},
+			key:      "cookie",
+			want:     true,
+		},
+		{
+			name:     "qualified no match",
+			patterns: []string{"http.headers.*"},
+			groups:   []string{"http"},
+			key:      "method",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchKey(tt.patterns, tt.groups, tt.key); got != tt.want {
+				t.Errorf("unexpected result: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
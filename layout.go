@@ -0,0 +1,173 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"unicode/utf8"
+)
+
+// Layout controls the order in which a [CLIHandler] renders the
+// components of a record.
+type Layout int
+
+// Layouts.
+const (
+	// TimeFirst renders the time, the level, the source and the
+	// message, followed by the attributes (e.g.
+	// "12:04:05 INFO message key=val").
+	TimeFirst Layout = iota
+
+	// MessageFirst renders the level and the message, followed by
+	// the time and the source between parentheses and the
+	// attributes (e.g. "INFO message  (12:04:05) key=val"). It
+	// favors readability for the users of interactive tools.
+	MessageFirst
+)
+
+// String returns a name for the layout.
+func (l Layout) String() string {
+	switch l {
+	case TimeFirst:
+		return "time-first"
+	case MessageFirst:
+		return "message-first"
+	default:
+		return fmt.Sprintf("Layout(%d)", int(l))
+	}
+}
+
+// appendHeader appends to buf the components of r that precede the
+// attributes, according to the configured layout.
+func (h *CLIHandler) appendHeader(buf *attrBuffer, out *output, r slog.Record) {
+	b := buf.line
+	switch h.opts.Layout {
+	case MessageFirst:
+		if h.opts.Delta == DeltaPrefix && h.appendDelta(b, out, r, true) {
+			b.WriteByte(' ')
+		}
+		if h.appendLevel(b, out, r) {
+			b.WriteByte(' ')
+		}
+		buf.msg = len(*b)
+		h.appendMessage(b, r)
+		h.padMessage(buf)
+
+		mark, pad := len(*b), buf.pad
+		buf.flushPad()
+		b.WriteString("  (")
+		hasTime := h.appendTime(b, out, r)
+		if hasTime {
+			b.WriteByte(' ')
+		}
+		switch {
+		case h.appendSource(b, out, r):
+			b.WriteByte(')')
+		case hasTime:
+			// Replace the trailing space.
+			(*b)[len(*b)-1] = ')'
+		default:
+			*b = (*b)[:mark]
+			buf.pad = pad
+		}
+	default:
+		if h.appendTime(b, out, r) {
+			b.WriteByte(' ')
+		}
+		if h.opts.Delta == DeltaPrefix && h.appendDelta(b, out, r, true) {
+			b.WriteByte(' ')
+		}
+		if h.appendLevel(b, out, r) {
+			b.WriteByte(' ')
+		}
+		if h.appendSource(b, out, r) {
+			b.WriteByte(' ')
+		}
+		buf.msg = len(*b)
+		h.appendMessage(b, r)
+		h.padMessage(buf)
+	}
+}
+
+// padMessage schedules the padding required to make the message in
+// buf at least MessageWidth characters wide. The padding is written
+// by the next component, if any, so lines do not end with spaces.
+func (h *CLIHandler) padMessage(buf *attrBuffer) {
+	if h.opts.MessageWidth > 0 {
+		buf.pad = h.opts.MessageWidth - utf8.RuneCount((*buf.line)[buf.msg:])
+	}
+}
+
+// appendTime appends to b the time of r. It reports whether anything
+// was appended.
+func (h *CLIHandler) appendTime(b *buffer, out *output, r slog.Record) bool {
+	if h.opts.OmitTime || r.Time.IsZero() {
+		return false
+	}
+	v, ok := h.builtin(slog.TimeKey, slog.TimeValue(r.Time.Round(0)))
+	if !ok {
+		return false
+	}
+	style := out.style(h.opts.Theme.Time)
+	b.WriteString(style)
+	h.appendTimeValue(b, v)
+	endStyle(b, style)
+	return true
+}
+
+// appendLevel appends to b the level of r, rendered according to the
+// configured icon mode. It reports whether anything was appended.
+func (h *CLIHandler) appendLevel(b *buffer, out *output, r slog.Record) bool {
+	level, ok := h.levelLabel(r.Level)
+	if !ok {
+		return false
+	}
+	style := out.style(h.opts.Theme.levelStyle(r.Level))
+	switch h.opts.Icons {
+	case IconsPrefix:
+		writeStyled(b, style, h.opts.IconSet.icon(r.Level))
+		b.WriteByte(' ')
+		fallthrough
+	case IconsOff:
+		writeStyled(b, style, level)
+		appendPad(b, level, h.opts.LevelWidth)
+	case IconsReplace:
+		writeStyled(b, style, h.opts.IconSet.icon(r.Level))
+	}
+	return true
+}
+
+// appendSource appends to b the source code position of r, if
+// AddSource is set. It reports whether anything was appended.
+func (h *CLIHandler) appendSource(b *buffer, out *output, r slog.Record) bool {
+	v, ok := h.source(r)
+	if !ok {
+		return false
+	}
+	b.WriteString(h.formatSource(v, out.tty))
+	return true
+}
+
+// source returns the source code position of r after applying
+// ReplaceAttr. It returns false if AddSource is not set or the
+// position must be omitted.
+func (h *CLIHandler) source(r slog.Record) (slog.Value, bool) {
+	if !h.opts.AddSource || r.PC == 0 {
+		return slog.Value{}, false
+	}
+	fs := runtime.CallersFrames([]uintptr{r.PC})
+	f, _ := fs.Next()
+	src := &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+	return h.builtin(slog.SourceKey, slog.AnyValue(src))
+}
+
+// appendMessage appends to b the message of r. It reports whether
+// anything was appended.
+func (h *CLIHandler) appendMessage(b *buffer, r slog.Record) bool {
+	v, ok := h.builtin(slog.MessageKey, slog.StringValue(r.Message))
+	if !ok {
+		return false
+	}
+	h.appendEscaped(b, v.String())
+	return true
+}
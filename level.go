@@ -0,0 +1,166 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Levels defined by clilog in addition to the standard [slog] levels.
+// Applications can log at these levels using [slog.Logger.Log].
+const (
+	LevelTrace  slog.Level = slog.LevelDebug - 4
+	LevelNotice slog.Level = slog.LevelInfo + 2
+	LevelFatal  slog.Level = slog.LevelError + 4
+)
+
+// baseLevels are the named levels, sorted by decreasing severity.
+var baseLevels = []struct {
+	level slog.Level
+	name  string
+}{
+	{LevelFatal, "FATAL"},
+	{slog.LevelError, "ERROR"},
+	{slog.LevelWarn, "WARN"},
+	{LevelNotice, "NOTICE"},
+	{slog.LevelInfo, "INFO"},
+	{slog.LevelDebug, "DEBUG"},
+	{LevelTrace, "TRACE"},
+}
+
+// baseLevel returns the closest named level that is lower or equal
+// than level. If there is no such level, it returns LevelTrace.
+func baseLevel(level slog.Level) (slog.Level, string) {
+	for _, b := range baseLevels {
+		if level >= b.level {
+			return b.level, b.name
+		}
+	}
+	b := baseLevels[len(baseLevels)-1]
+	return b.level, b.name
+}
+
+// levelValues contains a value per named level.
+type levelValues struct {
+	trace, debug, info, notice, warn, error, fatal string
+}
+
+// selectLevel returns the value of the closest named level that is
+// lower or equal than level. If the value of LevelTrace, LevelNotice
+// or LevelFatal is empty, the value of the closest standard level is
+// returned instead: debug for trace, info for notice and error for
+// fatal.
+func selectLevel(level slog.Level, v levelValues) string {
+	base, _ := baseLevel(level)
+	switch base {
+	case LevelTrace:
+		return firstNonEmpty(v.trace, v.debug)
+	case slog.LevelDebug:
+		return v.debug
+	case slog.LevelInfo:
+		return v.info
+	case LevelNotice:
+		return firstNonEmpty(v.notice, v.info)
+	case slog.LevelWarn:
+		return v.warn
+	case slog.LevelError:
+		return v.error
+	default:
+		return firstNonEmpty(v.fatal, v.error)
+	}
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// LevelString returns a name for the level. Besides the standard
+// [slog] levels, it names LevelTrace, LevelNotice and LevelFatal. If
+// the level is between named levels, the name is followed by the
+// offset from the closest lower one (e.g. "NOTICE+1" or "TRACE-2").
+func LevelString(level slog.Level) string {
+	base, name := baseLevel(level)
+	if level == base {
+		return name
+	}
+	return fmt.Sprintf("%v%+d", name, level-base)
+}
+
+// ParseLevel parses a level name as returned by [LevelString]. Names
+// are case-insensitive, "WARNING" is accepted as an alias of "WARN"
+// and the offset is optional (e.g. "debug", "Info+2" or "error-1").
+// Numeric levels (e.g. "-4") are also accepted.
+func ParseLevel(s string) (slog.Level, error) {
+	name, offset := s, 0
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		n, err := strconv.Atoi(s[i:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid level offset in %q: %w", s, err)
+		}
+		name, offset = s[:i], n
+	}
+	if name == "" && s != "" {
+		return slog.Level(offset), nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		return slog.Level(n + offset), nil
+	}
+	if strings.EqualFold(name, "WARNING") {
+		name = "WARN"
+	}
+	for _, b := range baseLevels {
+		if strings.EqualFold(name, b.name) {
+			return b.level + slog.Level(offset), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level %q: valid levels are trace, debug, info, notice, warn, error and fatal, optionally followed by an offset (e.g. \"debug+2\")", s)
+}
+
+// levelNames returns a map with the level names defined by the
+// provided [HandlerOptions.LevelNames] map.
+func levelNames(names map[slog.Leveler]string) map[slog.Level]string {
+	if len(names) == 0 {
+		return nil
+	}
+	m := make(map[slog.Level]string, len(names))
+	for l, name := range names {
+		m[l.Level()] = name
+	}
+	return m
+}
+
+// levelString returns the string representation of the level value
+// v. Values of type [slog.Level] are named after
+// [HandlerOptions.LevelNames] or, if the level is not there, by
+// [LevelString].
+func (h *CLIHandler) levelString(v slog.Value) string {
+	level, ok := v.Any().(slog.Level)
+	if v.Kind() != slog.KindAny || !ok {
+		return v.String()
+	}
+	return h.levelName(level)
+}
+
+// levelName returns the name of the provided level.
+func (h *CLIHandler) levelName(level slog.Level) string {
+	if name, ok := h.levels[level]; ok {
+		return name
+	}
+	return LevelString(level)
+}
+
+// appendPad appends to b the padding required to make s at least
+// width runes long.
+func appendPad(b *buffer, s string, width int) {
+	for n := width - utf8.RuneCountInString(s); n > 0; n-- {
+		b.WriteByte(' ')
+	}
+}
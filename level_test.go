@@ -0,0 +1,35 @@
+package clilog
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestLevelString(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{LevelTrace - 2, "TRACE-2"},
+		{LevelTrace, "TRACE"},
+		{LevelTrace + 1, "TRACE+1"},
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo, "INFO"},
+		{slog.LevelInfo + 1, "INFO+1"},
+		{LevelNotice, "NOTICE"},
+		{LevelNotice + 1, "NOTICE+1"},
+		{slog.LevelWarn, "WARN"},
+		{slog.LevelError, "ERROR"},
+		{slog.LevelError + 2, "ERROR+2"},
+		{LevelFatal, "FATAL"},
+		{LevelFatal + 1, "FATAL+1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := LevelString(tt.level); got != tt.want {
+				t.Errorf("unexpected level name: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
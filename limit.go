@@ -0,0 +1,125 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"unicode/utf8"
+)
+
+// Markers of the contents omitted because of MaxAttrs and
+// MaxLineBytes. logfmt lines use an attribute, so they can still be
+// parsed.
+const (
+	truncatedMarker       = ellipsis + "truncated"
+	truncatedLogfmtMarker = "truncated=true"
+)
+
+// truncatedMarker returns the marker of the contents omitted because
+// of MaxAttrs and MaxLineBytes.
+func (h *CLIHandler) truncatedMarker() string {
+	if h.opts.Format == FormatLogfmt {
+		return truncatedLogfmtMarker
+	}
+	return truncatedMarker
+}
+
+// limitAttrs returns the first MaxAttrs attributes of the processed
+// attributes attrs that are rendered. The discarded attributes are not
+// counted. It also reports whether any rendered attribute was
+// omitted.
+func (h *CLIHandler) limitAttrs(attrs []slog.Attr) ([]slog.Attr, bool) {
+	rendered := 0
+	for i, a := range attrs {
+		if isEmptyAttr(a) {
+			continue
+		}
+		if rendered == h.opts.MaxAttrs {
+			return attrs[:i], true
+		}
+		rendered++
+	}
+	return attrs, false
+}
+
+// isEmptyAttr reports whether the processed attribute a renders
+// nothing, because it was discarded or it is a group without
+// attributes to render.
+func isEmptyAttr(a slog.Attr) bool {
+	if a.Value.Kind() != slog.KindGroup {
+		return a.Equal(slog.Attr{})
+	}
+	for _, a := range a.Value.Group() {
+		if !isEmptyAttr(a) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateRecord truncates the record in b, which ends with a
+// newline, to size bytes, excluding the newline. The escape sequences
+// and the UTF-8 encoded characters are never split, and the styles and
+// hyperlinks open at the truncation point are closed. If logfmt is
+// true, the quoted value open at the truncation point is closed and
+// the marker is rendered as an attribute.
+func truncateRecord(b *buffer, size int, logfmt bool) {
+	record := (*b)[:len(*b)-1]
+	if len(record) <= size {
+		return
+	}
+
+	var i int
+	styled, linked, quoted, escaped := false, false, false, false
+	for i < len(record) {
+		n := ansiLen(record[i:])
+		seq := n > 0
+		if !seq {
+			_, n = utf8.DecodeRune(record[i:])
+		}
+		if i+n > size {
+			break
+		}
+		if seq {
+			if s := record[i : i+n]; bytes.HasPrefix(s, []byte("\x1b]8;")) {
+				linked = !bytes.HasSuffix(s, []byte(";\x1b\\"))
+			} else {
+				styled = true
+			}
+		}
+		if logfmt {
+			switch c := record[i]; {
+			case escaped:
+				escaped = false
+			case c == '\\' && quoted:
+				escaped = true
+			case c == '"':
+				quoted = !quoted
+			}
+		}
+		i += n
+	}
+
+	*b = record[:i]
+	if logfmt {
+		if quoted {
+			if escaped {
+				// Drop the dangling backslash.
+				*b = (*b)[:len(*b)-1]
+			}
+			b.WriteByte('"')
+		}
+		*b = bytes.TrimRight(*b, " ")
+		b.WriteByte(' ')
+		b.WriteString(truncatedLogfmtMarker)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteString(truncatedMarker)
+	if linked {
+		b.WriteString("\x1b]8;;\x1b\\")
+	}
+	if styled {
+		b.WriteString(ansiReset)
+	}
+	b.WriteByte('\n')
+}
@@ -0,0 +1,161 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTruncateRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		size   int
+		logfmt bool
+		want   string
+	}{
+		{
+			name:   "short",
+			record: "hello\n",
+			size:   5,
+			want:   "hello\n",
+		},
+		{
+			name:   "long",
+			record: "hello world\n",
+			size:   5,
+			want:   "hello…truncated\n",
+		},
+		{
+			name:   "multi-byte",
+			record: "ñandú\n",
+			size:   5,
+			want:   "ñand…truncated\n",
+		},
+		{
+			name:   "style",
+			record: "\x1b[1mhello\x1b[0m world\n",
+			size:   8,
+			want:   "\x1b[1mhell…truncated\x1b[0m\n",
+		},
+		{
+			name:   "escape sequence",
+			record: "hi\x1b[1mhello\x1b[0m\n",
+			size:   4,
+			want:   "hi…truncated\n",
+		},
+		{
+			name:   "blocks",
+			record: "INFO message\n  out:\n    line 1\n",
+			size:   19,
+			want:   "INFO message\n  out:…truncated\n",
+		},
+		{
+			name:   "logfmt",
+			record: "level=INFO msg=message a=1 b=2\n",
+			size:   26,
+			logfmt: true,
+			want:   "level=INFO msg=message a=1 truncated=true\n",
+		},
+		{
+			name:   "logfmt quoted",
+			record: `level=INFO msg="hello world" a=1` + "\n",
+			size:   20,
+			logfmt: true,
+			want:   `level=INFO msg="hell" truncated=true` + "\n",
+		},
+		{
+			name:   "logfmt escaped quote",
+			record: `level=INFO msg="a\"b"` + "\n",
+			size:   18,
+			logfmt: true,
+			want:   `level=INFO msg="a" truncated=true` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := buffer(tt.record)
+			truncateRecord(&b, tt.size, tt.logfmt)
+			if got := string(b); got != tt.want {
+				t.Errorf("unexpected record: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_MaxAttrs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts HandlerOptions
+		want string
+	}{
+		{
+			name: "not truncated",
+			opts: HandlerOptions{MaxAttrs: 4},
+			want: "INFO message w=0 c=3 g.x=0 a=1 b=2\n",
+		},
+		{
+			name: "truncated",
+			opts: HandlerOptions{MaxAttrs: 2},
+			want: "INFO message w=0 c=3 g.x=0 …truncated\n",
+		},
+		{
+			name: "KeyOrder",
+			opts: HandlerOptions{MaxAttrs: 2, KeyOrder: KeyOrder{Sort: true}},
+			want: "INFO message w=0 a=1 b=2 …truncated\n",
+		},
+		{
+			name: "DropKeys",
+			opts: HandlerOptions{MaxAttrs: 2, DropKeys: []string{"c", "g"}},
+			want: "INFO message w=0 a=1 b=2\n",
+		},
+		{
+			name: "KeyOrder DropKeys",
+			opts: HandlerOptions{MaxAttrs: 1, DropKeys: []string{"a", "g"}, KeyOrder: KeyOrder{Sort: true}},
+			want: "INFO message w=0 b=2 …truncated\n",
+		},
+		{
+			name: "logfmt",
+			opts: HandlerOptions{MaxAttrs: 2, Format: FormatLogfmt},
+			want: "level=INFO msg=message w=0 c=3 g.x=0 truncated=true\n",
+		},
+		{
+			name: "bracketed groups",
+			opts: HandlerOptions{MaxAttrs: 1, GroupStyle: GroupsBracketed},
+			want: "INFO message w=0 g=[a=1] …truncated\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.opts.OmitTime = true
+			logger := slog.New(NewCLIHandler(&buf, &tt.opts)).With("w", 0)
+			if tt.opts.GroupStyle == GroupsBracketed {
+				logger.Info("message", slog.Group("g", "a", 1), "b", 2)
+			} else {
+				logger.Info("message", "c", 3, slog.Group("g", "x", 0), "a", 1, "b", 2)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_MaxLineBytes(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, MaxLineBytes: 32, LineEnding: LineEndingCRLF}))
+	logger.Info("message", "dump", strings.Repeat("x", 1<<20))
+	logger.Info("short")
+
+	want := "INFO message dump=xxxxxxxxxxxxxx…truncated\r\nINFO short\r\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
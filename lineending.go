@@ -0,0 +1,59 @@
+package clilog
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// LineEnding controls how a [CLIHandler] terminates the lines of the
+// records.
+type LineEnding int
+
+// Line endings.
+const (
+	// LineEndingLF terminates lines with "\n".
+	LineEndingLF LineEnding = iota
+
+	// LineEndingCRLF terminates lines with "\r\n", as needed by
+	// raw Windows consoles and some protocols.
+	LineEndingCRLF
+
+	// LineEndingNone omits the trailing newline of the records,
+	// so they can be embedded in another framing layer. The lines
+	// within a record (e.g. multi-line blocks) are still
+	// terminated with "\n".
+	LineEndingNone
+)
+
+// String returns a name for the line ending.
+func (e LineEnding) String() string {
+	switch e {
+	case LineEndingLF:
+		return "lf"
+	case LineEndingCRLF:
+		return "crlf"
+	case LineEndingNone:
+		return "none"
+	default:
+		return fmt.Sprintf("LineEnding(%d)", int(e))
+	}
+}
+
+// applyLineEnding replaces the newlines of the record in b according
+// to the configured line ending.
+func (h *CLIHandler) applyLineEnding(b *buffer) {
+	switch h.opts.LineEnding {
+	case LineEndingCRLF:
+		crlf := newBuffer()
+		defer crlf.free()
+		for _, c := range *b {
+			if c == '\n' {
+				crlf.WriteByte('\r')
+			}
+			crlf.WriteByte(c)
+		}
+		*b = append((*b)[:0], *crlf...)
+	case LineEndingNone:
+		*b = bytes.TrimSuffix(*b, []byte("\n"))
+	}
+}
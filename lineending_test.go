@@ -0,0 +1,63 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestCLIHandler_LineEnding(t *testing.T) {
+	tests := []struct {
+		name       string
+		lineEnding LineEnding
+		want       string
+	}{
+		{
+			name:       "lf",
+			lineEnding: LineEndingLF,
+			want:       "INFO first\nINFO second\n  out:\n    line 1\n    line 2\n",
+		},
+		{
+			name:       "crlf",
+			lineEnding: LineEndingCRLF,
+			want:       "INFO first\r\nINFO second\r\n  out:\r\n    line 1\r\n    line 2\r\n",
+		},
+		{
+			name:       "none",
+			lineEnding: LineEndingNone,
+			want:       "INFO firstINFO second\n  out:\n    line 1\n    line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, Multiline: true, LineEnding: tt.lineEnding}))
+			logger.Info("first")
+			logger.Info("second", "out", "line 1\nline 2")
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_LineEnding_group(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewCLIHandler(&buf, &HandlerOptions{Format: FormatGitHub, OmitTime: true, LineEnding: LineEndingCRLF})
+	if err := h.StartGroup("Build"); err != nil {
+		t.Fatalf("start group error: %v", err)
+	}
+	slog.New(h).Info("message")
+	if err := h.EndGroup(); err != nil {
+		t.Fatalf("end group error: %v", err)
+	}
+
+	want := "::group::Build\r\nINFO message\r\n::endgroup::\r\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// NewLineWriter returns an [io.WriteCloser] that logs every line
+// written to it as a record with the provided level and attributes.
+// It allows to log the output of a subprocess. For instance:
+//
+//	w := clilog.NewLineWriter(logger, slog.LevelInfo, slog.String("cmd", "make"))
+//	defer w.Close()
+//	cmd := exec.Command("make")
+//	cmd.Stdout, cmd.Stderr = w, w
+//
+// The trailing carriage returns and the empty lines are ignored.
+// Close logs the last line if it is not terminated by a newline. It
+// is safe to write to a LineWriter concurrently.
+func NewLineWriter(logger *slog.Logger, level slog.Level, attrs ...slog.Attr) io.WriteCloser {
+	return &lineWriter{h: logger.Handler().WithAttrs(attrs), level: level}
+}
+
+// NewRelogWriter returns an [io.WriteCloser] like the one returned by
+// [NewLineWriter] that parses the lines written by a
+// [slog.JSONHandler] or in logfmt format, such as the logs of another
+// Go program, and logs them as the original records with their time,
+// level, message and attributes, followed by the provided ones. The
+// lines are parsed as in [PrettyPrint]. The rest of lines are logged
+// as messages with the provided level.
+func NewRelogWriter(logger *slog.Logger, level slog.Level, attrs ...slog.Attr) io.WriteCloser {
+	return &lineWriter{h: logger.Handler().WithAttrs(attrs), level: level, relog: true}
+}
+
+// lineWriter is the [io.WriteCloser] returned by NewLineWriter and
+// NewRelogWriter.
+type lineWriter struct {
+	h     slog.Handler
+	level slog.Level
+	relog bool // parse structured lines
+
+	mu  sync.Mutex
+	buf []byte // incomplete line
+}
+
+// Write logs the complete lines in p. Incomplete lines are buffered
+// until the newline is written.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	start := 0
+	for {
+		i := bytes.IndexByte(w.buf[start:], '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(w.buf[start : start+i])
+		start += i + 1
+	}
+	w.buf = append(w.buf[:0], w.buf[start:]...)
+	return len(p), nil
+}
+
+// Close logs the buffered incomplete line, if any.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.logLine(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// logLine logs line as a record.
+func (w *lineWriter) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) == 0 {
+		return
+	}
+	r, ok := w.parseLine(line)
+	if !ok {
+		r = slog.NewRecord(time.Now(), w.level, string(line), 0)
+	}
+	ctx := context.Background()
+	if !w.h.Enabled(ctx, r.Level) {
+		return
+	}
+	_ = w.h.Handle(ctx, r)
+}
+
+// parseLine parses line as a structured log line if relogging is
+// enabled. Records without time get the current time.
+func (w *lineWriter) parseLine(line []byte) (slog.Record, bool) {
+	if !w.relog {
+		return slog.Record{}, false
+	}
+	r, err := parseRecord(bytes.TrimSpace(line))
+	if err != nil {
+		return slog.Record{}, false
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	return r, true
+}
@@ -0,0 +1,114 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestLineWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{
+			name:   "lines",
+			writes: []string{"first line\nsecond line\n"},
+			want:   "INFO first line cmd=make\nINFO second line cmd=make\n",
+		},
+		{
+			name:   "split lines",
+			writes: []string{"fir", "st line\nsec", "ond", " line\n"},
+			want:   "INFO first line cmd=make\nINFO second line cmd=make\n",
+		},
+		{
+			name:   "crlf and empty lines",
+			writes: []string{"first line\r\n\n\r\nsecond line\r\n"},
+			want:   "INFO first line cmd=make\nINFO second line cmd=make\n",
+		},
+		{
+			name:   "unterminated line",
+			writes: []string{"first line\nlast"},
+			want:   "INFO first line cmd=make\nINFO last cmd=make\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}))
+			w := NewLineWriter(logger, slog.LevelInfo, slog.String("cmd", "make"))
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil {
+					t.Fatalf("write error: %v", err)
+				}
+				if n != len(s) {
+					t.Errorf("unexpected number of bytes: got: %v, want: %v", n, len(s))
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineWriter_level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}))
+
+	debug := NewLineWriter(logger, slog.LevelDebug)
+	debug.Write([]byte("hidden\n"))
+	warn := NewLineWriter(logger, slog.LevelWarn)
+	warn.Write([]byte("shown\n"))
+
+	if got, want := buf.String(), "WARN shown\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestRelogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{Level: slog.LevelDebug}))
+
+	w := NewRelogWriter(logger, slog.LevelInfo, slog.String("cmd", "tool"))
+	w.Write([]byte(`{"time":"2023-09-20T12:24:43Z","level":"WARN","msg":"disk full","free":0}` + "\n"))
+	w.Write([]byte("time=2023-09-20T12:24:44Z level=DEBUG msg=connecting addr=localhost\n"))
+	w.Write([]byte("plain output\n"))
+	w.Close()
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	want := []string{
+		"2023-09-20T12:24:43Z WARN disk full cmd=tool free=0",
+		"2023-09-20T12:24:44Z DEBUG connecting cmd=tool addr=localhost",
+	}
+	for i, w := range want {
+		if got := string(lines[i]); got != w {
+			t.Errorf("unexpected line %d: got: %q, want: %q", i, got, w)
+		}
+	}
+	if !bytes.HasSuffix(lines[2], []byte(" INFO plain output cmd=tool")) {
+		t.Errorf("unexpected line 2: %q", lines[2])
+	}
+}
+
+func TestRelogWriter_level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}))
+
+	w := NewRelogWriter(logger, slog.LevelInfo)
+	w.Write([]byte(`{"level":"DEBUG","msg":"hidden"}` + "\n"))
+	w.Write([]byte(`{"level":"ERROR","msg":"shown"}` + "\n"))
+
+	if got, want := buf.String(), "ERROR shown\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
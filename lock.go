@@ -0,0 +1,18 @@
+package clilog
+
+import "sync"
+
+// NoLock is a [sync.Locker] that does nothing. Setting
+// [HandlerOptions.Lock] to NoLock disables the locking of a
+// [CLIHandler], which is only safe if the caller serializes the calls
+// to the handler and the handlers derived from it.
+var NoLock sync.Locker = noLock{}
+
+// noLock implements NoLock.
+type noLock struct{}
+
+// Lock does nothing.
+func (noLock) Lock() {}
+
+// Unlock does nothing.
+func (noLock) Unlock() {}
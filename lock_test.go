@@ -0,0 +1,76 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// overlapWriter is a writer that records whether it is called
+// concurrently.
+type overlapWriter struct {
+	buf     bytes.Buffer
+	busy    atomic.Bool
+	overlap atomic.Bool
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if !w.busy.CompareAndSwap(false, true) {
+		w.overlap.Store(true)
+		return len(p), nil
+	}
+	defer w.busy.Store(false)
+	runtime.Gosched()
+	return w.buf.Write(p)
+}
+
+func TestCLIHandler_Lock(t *testing.T) {
+	var (
+		w  overlapWriter
+		mu sync.Mutex
+	)
+
+	loggers := []*slog.Logger{
+		slog.New(NewCLIHandler(&w, &HandlerOptions{OmitTime: true, Lock: &mu})),
+		slog.New(NewCLIHandler(&w, &HandlerOptions{OmitTime: true, Lock: &mu})),
+	}
+
+	var wg sync.WaitGroup
+	for _, logger := range loggers {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(logger *slog.Logger) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					logger.Info("message", "j", j)
+				}
+			}(logger)
+		}
+	}
+	wg.Wait()
+
+	if w.overlap.Load() {
+		t.Error("concurrent writes")
+	}
+	if got, want := strings.Count(w.buf.String(), "\n"), 800; got != want {
+		t.Errorf("unexpected number of lines: got: %v, want: %v", got, want)
+	}
+}
+
+func TestCLIHandler_NoLock(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, Lock: NoLock})
+	logger := slog.New(h)
+	logger.Info("first")
+	h.SetColor(ColorNever)
+	logger.Warn("second")
+
+	if got, want := buf.String(), "INFO first\nWARN second\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package clilog
+
+import (
+	"io"
+	"log/slog"
+)
+
+// New returns a new [slog.Logger] backed by a [CLIHandler] that writes
+// to w. If opts is nil, the options returned by [DetectEnvironment]
+// are used.
+func New(w io.Writer, opts *HandlerOptions) *slog.Logger {
+	if opts == nil {
+		return slog.New(NewAutoHandler(w))
+	}
+	return slog.New(NewCLIHandler(w, opts))
+}
+
+// SetDefault makes the logger returned by [New] the default logger,
+// so it is used by the top-level functions of the [slog] and [log]
+// packages. It returns the new default logger.
+func SetDefault(w io.Writer, opts *HandlerOptions) *slog.Logger {
+	logger := New(w, opts)
+	slog.SetDefault(logger)
+	return logger
+}
@@ -0,0 +1,56 @@
+package clilog
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := New(&buf, &HandlerOptions{OmitTime: true})
+	logger.Info("message", "a", 1)
+
+	want := "INFO message a=1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestNew_auto(t *testing.T) {
+	for _, key := range []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "TF_BUILD", "TERM", "NO_COLOR", "CLICOLOR"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("GITLAB_CI", "true")
+
+	h, ok := New(&bytes.Buffer{}, nil).Handler().(*CLIHandler)
+	if !ok {
+		t.Fatal("handler is not a CLIHandler")
+	}
+	if h.opts.Format != FormatGitLab {
+		t.Errorf("unexpected format: got: %v, want: %v", h.opts.Format, FormatGitLab)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	defer func(logger *slog.Logger, flags int) {
+		slog.SetDefault(logger)
+		log.SetFlags(flags)
+	}(slog.Default(), log.Flags())
+
+	var buf bytes.Buffer
+
+	logger := SetDefault(&buf, &HandlerOptions{OmitTime: true})
+	if slog.Default() != logger {
+		t.Error("the default logger was not set")
+	}
+	slog.Warn("from slog")
+	log.Print("from log")
+
+	want := "WARN from slog\nINFO from log\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// Middleware wraps a [slog.Handler] with another one that transforms
+// the records before passing them to it.
+type Middleware func(h slog.Handler) slog.Handler
+
+// Chain returns h wrapped by the provided middlewares. Records pass
+// through the middlewares in order, so the first middleware is the
+// outermost one. For instance:
+//
+//	h := clilog.Chain(clilog.NewCLIHandler(os.Stderr, nil),
+//		clilog.Filter(keep),
+//		clilog.Dedupe(nil),
+//	)
+func Chain(h slog.Handler, mws ...Middleware) slog.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Filter returns a [Middleware] that wraps handlers with
+// [FilterHandler].
+func Filter(keep func(ctx context.Context, r slog.Record) bool) Middleware {
+	return func(h slog.Handler) slog.Handler {
+		return FilterHandler(h, keep)
+	}
+}
+
+// Dedupe returns a [Middleware] that wraps handlers with a
+// [DedupeHandler]. Programs that need to call Flush should use
+// NewDedupeHandler instead.
+func Dedupe(opts *DedupeOptions) Middleware {
+	return func(h slog.Handler) slog.Handler {
+		return NewDedupeHandler(h, opts)
+	}
+}
+
+// Sample returns a [Middleware] that wraps handlers with a
+// [SamplerHandler].
+func Sample(opts *SamplerOptions) Middleware {
+	return func(h slog.Handler) slog.Handler {
+		return NewSamplerHandler(h, opts)
+	}
+}
+
+// RateLimit returns a [Middleware] that wraps handlers with a
+// [RateLimitHandler].
+func RateLimit(opts *RateLimitOptions) Middleware {
+	return func(h slog.Handler) slog.Handler {
+		return NewRateLimitHandler(h, opts)
+	}
+}
+
+// RewriteAttrs returns a [Middleware] that rewrites the attributes of
+// the records, including those added with WithAttrs, before passing
+// them to the wrapped handler. Like [slog.HandlerOptions.ReplaceAttr],
+// fn is called for every non-group attribute with the groups that
+// contain it, and the attributes it returns empty are discarded.
+// Unlike ReplaceAttr, fn is not called for the built-in attributes.
+func RewriteAttrs(fn func(groups []string, a slog.Attr) slog.Attr) Middleware {
+	return func(h slog.Handler) slog.Handler {
+		return &rewriteHandler{h: h, fn: fn}
+	}
+}
+
+// rewriteHandler is the [slog.Handler] created by RewriteAttrs.
+type rewriteHandler struct {
+	h      slog.Handler
+	fn     func(groups []string, a slog.Attr) slog.Attr
+	groups []string // groups from WithGroup
+}
+
+// Enabled reports whether the underlying handler handles records at
+// the given level.
+func (h *rewriteHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle passes a copy of the record with its attributes rewritten to
+// the underlying handler.
+func (h *rewriteHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.rewrite(h.groups, a); ok {
+			nr.AddAttrs(a)
+		}
+		return true
+	})
+	return h.h.Handle(ctx, nr)
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both
+// the receiver's attributes and the arguments, rewritten.
+func (h *rewriteHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	rewritten := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a, ok := h.rewrite(h.groups, a); ok {
+			rewritten = append(rewritten, a)
+		}
+	}
+	return &rewriteHandler{h: h.h.WithAttrs(rewritten), fn: h.fn, groups: h.groups}
+}
+
+// WithGroup returns a new Handler with the given group appended to
+// the receiver's existing groups.
+func (h *rewriteHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &rewriteHandler{h: h.h.WithGroup(name), fn: h.fn, groups: append(slices.Clip(h.groups), name)}
+}
+
+// rewrite returns the result of calling fn on a or, if a is a group,
+// on its members. It returns false if the attribute must be
+// discarded.
+func (h *rewriteHandler) rewrite(groups []string, a slog.Attr) (slog.Attr, bool) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		a = h.fn(groups, a)
+		return a, !a.Equal(slog.Attr{})
+	}
+
+	if a.Key != "" {
+		groups = append(slices.Clip(groups), a.Key)
+	}
+	var members []slog.Attr
+	for _, m := range a.Value.Group() {
+		if m, ok := h.rewrite(groups, m); ok {
+			members = append(members, m)
+		}
+	}
+	if len(members) == 0 {
+		return slog.Attr{}, false
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}, true
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *rewriteHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
@@ -0,0 +1,56 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		order []string
+	)
+
+	mw := func(name string) Middleware {
+		return Filter(func(ctx context.Context, r slog.Record) bool {
+			order = append(order, name)
+			return true
+		})
+	}
+	h := Chain(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), mw("a"), mw("b"), Dedupe(nil))
+	logger := slog.New(h)
+	logger.Info("message")
+	logger.Info("message")
+
+	if got, want := strings.Join(order, ","), "a,b,a,b"; got != want {
+		t.Errorf("unexpected order: got: %v, want: %v", got, want)
+	}
+	if got, want := buf.String(), "INFO message\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestRewriteAttrs(t *testing.T) {
+	var buf bytes.Buffer
+
+	rewrite := func(groups []string, a slog.Attr) slog.Attr {
+		switch {
+		case a.Key == "password":
+			return slog.Attr{}
+		case a.Key == "id" && len(groups) > 0:
+			a.Key = strings.Join(groups, "_") + "_id"
+		}
+		return a
+	}
+	h := Chain(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), RewriteAttrs(rewrite))
+	logger := slog.New(h).With("password", "secret", "id", 1).WithGroup("req")
+	logger.Info("message", "id", 2, slog.Group("user", "id", 3, "password", "secret"), slog.Group("auth", "password", "secret"))
+
+	want := "INFO message id=1 req.req_id=2 req.user.req_user_id=3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
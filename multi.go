@@ -0,0 +1,85 @@
+package clilog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// MultiHandler returns a [slog.Handler] that fans out every record to
+// all the provided handlers. Each handler decides independently
+// whether it handles a record. The errors returned by the handlers
+// are joined.
+func MultiHandler(handlers ...slog.Handler) slog.Handler {
+	return &multiHandler{handlers: slices.Clone(handlers)}
+}
+
+// multiHandler is the [slog.Handler] returned by MultiHandler.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+// Enabled reports whether any of the handlers handles records at the
+// given level.
+func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range h.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes the record to the handlers that are enabled for its
+// level.
+func (h *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range h.handlers {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new Handler whose handlers are the result of
+// calling WithAttrs on each of the receiver's handlers.
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, h := range h.handlers {
+		handlers[i] = h.WithAttrs(slices.Clone(attrs))
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// WithGroup returns a new Handler whose handlers are the result of
+// calling WithGroup on each of the receiver's handlers.
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, h := range h.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// wrapped returns the handlers wrapped by h.
+func (h *multiHandler) wrapped() []slog.Handler {
+	return h.handlers
+}
+
+// NewTeeHandler returns a [slog.Handler] that writes records both to a
+// [CLIHandler] and to a [slog.JSONHandler]. Typically, w is the
+// console and file is a log file. Each handler has its own options,
+// so the level of the CLIHandler does not gate the records written to
+// file.
+func NewTeeHandler(w io.Writer, opts *HandlerOptions, file io.Writer, fileOpts *slog.HandlerOptions) slog.Handler {
+	return MultiHandler(
+		NewCLIHandler(w, opts),
+		slog.NewJSONHandler(file, fileOpts),
+	)
+}
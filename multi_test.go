@@ -0,0 +1,91 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestMultiHandler(t *testing.T) {
+	var infoBuf, debugBuf bytes.Buffer
+
+	h := MultiHandler(
+		NewCLIHandler(&infoBuf, &HandlerOptions{OmitTime: true}),
+		NewCLIHandler(&debugBuf, &HandlerOptions{OmitTime: true, Level: slog.LevelDebug}),
+	)
+	logger := slog.New(h).With("a", 1).WithGroup("g")
+
+	if !h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("MultiHandler is not enabled for debug")
+	}
+	if h.Enabled(context.Background(), LevelTrace) {
+		t.Errorf("MultiHandler is enabled for trace")
+	}
+
+	logger.Debug("debug", "b", 2)
+	logger.Info("info", "b", 3)
+
+	if got, want := infoBuf.String(), "INFO info a=1 g.b=3\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := debugBuf.String(), "DEBUG debug a=1 g.b=2\nINFO info a=1 g.b=3\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestMultiHandler_errors(t *testing.T) {
+	errA := errors.New("error a")
+	errB := errors.New("error b")
+
+	h := MultiHandler(
+		NewCLIHandler(errWriter{errA}, nil),
+		NewCLIHandler(&bytes.Buffer{}, nil),
+		NewCLIHandler(errWriter{errB}, nil),
+	)
+
+	r := slog.NewRecord(testTime, slog.LevelInfo, "message", 0)
+	err := h.Handle(context.Background(), r)
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestNewTeeHandler(t *testing.T) {
+	var console, file bytes.Buffer
+
+	h := NewTeeHandler(
+		&console, &HandlerOptions{OmitTime: true},
+		&file, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return a
+			},
+		},
+	)
+	logger := slog.New(h)
+
+	logger.Debug("debug", "a", 1)
+	logger.Info("info", "a", 2)
+
+	if got, want := console.String(), "INFO info a=2\n"; got != want {
+		t.Errorf("unexpected console output:\ngot:  %q\nwant: %q", got, want)
+	}
+	wantFile := `{"level":"DEBUG","msg":"debug","a":1}` + "\n" +
+		`{"level":"INFO","msg":"info","a":2}` + "\n"
+	if got := file.String(); got != wantFile {
+		t.Errorf("unexpected file output:\ngot:  %q\nwant: %q", got, wantFile)
+	}
+}
@@ -0,0 +1,75 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+)
+
+// appendScalar appends to b the string representation of the numeric,
+// boolean or duration value v.
+func (h *CLIHandler) appendScalar(b *buffer, v slog.Value) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		start := len(*b)
+		*b = strconv.AppendInt(*b, v.Int64(), 10)
+		h.groupDigits(b, start)
+	case slog.KindUint64:
+		start := len(*b)
+		*b = strconv.AppendUint(*b, v.Uint64(), 10)
+		h.groupDigits(b, start)
+	case slog.KindFloat64:
+		h.appendFloat(b, v.Float64())
+	case slog.KindBool:
+		*b = strconv.AppendBool(*b, v.Bool())
+	case slog.KindDuration:
+		*b = appendDuration(*b, v.Duration())
+	}
+}
+
+// appendFloat appends to b the float f formatted according to
+// FloatFormat and ExpThreshold.
+func (h *CLIHandler) appendFloat(b *buffer, f float64) {
+	if h.opts.FloatFormat != "" {
+		*b = fmt.Appendf(*b, h.opts.FloatFormat, f)
+		return
+	}
+	if h.opts.ExpThreshold <= 0 {
+		*b = strconv.AppendFloat(*b, f, 'g', -1, 64)
+		return
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && !math.IsInf(abs, 0) && !math.IsNaN(abs) {
+		if abs >= math.Pow10(h.opts.ExpThreshold) || abs < math.Pow10(-h.opts.ExpThreshold) {
+			format = 'e'
+		}
+	}
+	*b = strconv.AppendFloat(*b, f, format, -1, 64)
+}
+
+// groupDigits inserts DigitSeparator between every three digits of
+// the integer written to b from the offset start.
+func (h *CLIHandler) groupDigits(b *buffer, start int) {
+	sep := h.opts.DigitSeparator
+	if sep == "" {
+		return
+	}
+	if (*b)[start] == '-' {
+		start++
+	}
+	n := len(*b) - start
+	if n <= 3 {
+		return
+	}
+
+	var digits [20]byte
+	copy(digits[:], (*b)[start:])
+	*b = (*b)[:start]
+	for i := 0; i < n; i++ {
+		if i > 0 && (n-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteByte(digits[i])
+	}
+}
@@ -0,0 +1,74 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCLIHandler_numbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *HandlerOptions
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name:  "default",
+			attrs: []slog.Attr{slog.Int("i", 1234567), slog.Float64("f", 0.1), slog.Float64("e", 1e21)},
+			want:  `INFO message i=1234567 f=0.1 e=1e+21`,
+		},
+		{
+			name:  "FloatFormat",
+			opts:  &HandlerOptions{FloatFormat: "%.3f"},
+			attrs: []slog.Attr{slog.Float64("f", 2.0/3), slog.Float64("g", 1500)},
+			want:  `INFO message f=0.667 g=1500.000`,
+		},
+		{
+			name:  "ExpThreshold",
+			opts:  &HandlerOptions{ExpThreshold: 6},
+			attrs: []slog.Attr{slog.Float64("a", 1500.25), slog.Float64("b", 1.5e6), slog.Float64("c", 1e-7), slog.Float64("d", 0), slog.Float64("e", math.Inf(-1))},
+			want:  `INFO message a=1500.25 b=1.5e+06 c=1e-07 d=0 e=-Inf`,
+		},
+		{
+			name:  "ExpThreshold,FloatFormat",
+			opts:  &HandlerOptions{ExpThreshold: 2, FloatFormat: "%.1f"},
+			attrs: []slog.Attr{slog.Float64("a", 1500.25)},
+			want:  `INFO message a=1500.2`,
+		},
+		{
+			name:  "DigitSeparator",
+			opts:  &HandlerOptions{DigitSeparator: ","},
+			attrs: []slog.Attr{slog.Int("a", 1234567), slog.Int("b", -123456), slog.Int("c", 999), slog.Uint64("d", math.MaxUint64), slog.Int64("e", math.MinInt64)},
+			want:  `INFO message a=1,234,567 b=-123,456 c=999 d=18,446,744,073,709,551,615 e=-9,223,372,036,854,775,808`,
+		},
+		{
+			name:  "DigitSeparator,logfmt",
+			opts:  &HandlerOptions{DigitSeparator: "_", Format: FormatLogfmt},
+			attrs: []slog.Attr{slog.Int("a", 1234567)},
+			want:  `level=INFO msg=message a=1234567`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			opts := &HandlerOptions{}
+			if tt.opts != nil {
+				opts = tt.opts
+			}
+			opts.OmitTime = true
+
+			logger := slog.New(NewCLIHandler(&buf, opts))
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "message", tt.attrs...)
+
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.want {
+				t.Errorf("unexpected log line:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
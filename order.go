@@ -0,0 +1,54 @@
+package clilog
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+)
+
+// KeyOrder defines the order in which a [CLIHandler] renders the
+// attributes. The attributes are ordered among the attributes at the
+// same level: those passed to the same call to WithAttrs, those of the
+// same record or those of the same group. The zero KeyOrder keeps the
+// attributes in the order they were added.
+type KeyOrder struct {
+	// First is the list of keys rendered first, in the provided
+	// order (e.g. "id" or "name").
+	First []string
+
+	// Last is the list of keys rendered last, in the provided
+	// order (e.g. "err" or "duration").
+	Last []string
+
+	// Sort causes the rest of keys to be sorted alphabetically.
+	Sort bool
+}
+
+// enabled reports whether the attributes must be reordered.
+func (o *KeyOrder) enabled() bool {
+	return len(o.First) > 0 || len(o.Last) > 0 || o.Sort
+}
+
+// rank returns the position of key relative to the rest of keys:
+// negative for pinned first keys, positive for pinned last keys and
+// zero otherwise.
+func (o *KeyOrder) rank(key string) int {
+	if i := slices.Index(o.First, key); i >= 0 {
+		return i - len(o.First)
+	}
+	if i := slices.Index(o.Last, key); i >= 0 {
+		return i + 1
+	}
+	return 0
+}
+
+// sort sorts attrs in place.
+func (o *KeyOrder) sort(attrs []slog.Attr) {
+	slices.SortStableFunc(attrs, func(a, b slog.Attr) int {
+		ra, rb := o.rank(a.Key), o.rank(b.Key)
+		if ra != rb || ra != 0 || !o.Sort {
+			return cmp.Compare(ra, rb)
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+}
@@ -0,0 +1,59 @@
+package clilog
+
+import (
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestKeyOrder_sort(t *testing.T) {
+	tests := []struct {
+		name  string
+		order KeyOrder
+		keys  []string
+		want  []string
+	}{
+		{
+			name:  "zero",
+			order: KeyOrder{},
+			keys:  []string{"c", "err", "a", "id"},
+			want:  []string{"c", "err", "a", "id"},
+		},
+		{
+			name:  "first",
+			order: KeyOrder{First: []string{"id", "name"}},
+			keys:  []string{"c", "name", "a", "id"},
+			want:  []string{"id", "name", "c", "a"},
+		},
+		{
+			name:  "last",
+			order: KeyOrder{Last: []string{"err", "duration"}},
+			keys:  []string{"duration", "c", "err", "a"},
+			want:  []string{"c", "a", "err", "duration"},
+		},
+		{
+			name:  "sort",
+			order: KeyOrder{First: []string{"id"}, Last: []string{"err"}, Sort: true},
+			keys:  []string{"err", "c", "id", "b", "a", "b"},
+			want:  []string{"id", "a", "b", "b", "c", "err"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := make([]slog.Attr, len(tt.keys))
+			for i, k := range tt.keys {
+				attrs[i] = slog.Int(k, i)
+			}
+			tt.order.sort(attrs)
+
+			var got []string
+			for _, a := range attrs {
+				got = append(got, a.Key)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("unexpected order: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
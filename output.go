@@ -0,0 +1,117 @@
+package clilog
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+)
+
+// output is a destination of log lines.
+type output struct {
+	w     io.Writer
+	color bool // whether the output is colorized
+	tty   bool // whether the output is a terminal
+
+	fallback bool // whether the output replaced a failed one
+}
+
+// newOutput returns an output that writes to w. Colors are enabled
+// according to mode, unless w is a terminal unable to render them.
+func newOutput(w io.Writer, mode ColorMode) *output {
+	return &output{
+		w:     w,
+		color: colorEnabled(w, mode) && enableColors(w),
+		tty:   isTerminal(w),
+	}
+}
+
+// style returns the provided style if colors are enabled. Otherwise,
+// it returns an empty string.
+func (o *output) style(style string) string {
+	if !o.color {
+		return ""
+	}
+	return style
+}
+
+// NewCLIHandlerSplit returns a new [CLIHandler] that writes records
+// with a level lower than [HandlerOptions.SplitLevel] to stdout and
+// the rest to stderr. Colors are enabled independently for each
+// writer.
+func NewCLIHandlerSplit(stdout, stderr io.Writer, opts *HandlerOptions) *CLIHandler {
+	h := NewCLIHandler(stdout, opts)
+	h.state.errOut.Store(newOutput(stderr, h.opts.Color))
+	return h
+}
+
+// output returns the output of the records with the provided level.
+func (h *CLIHandler) output(level slog.Level) *output {
+	if h.opts.WriterFor != nil {
+		if out := h.levelOutput(level); out != nil {
+			return out
+		}
+	}
+	out, errOut := h.state.out.Load(), h.state.errOut.Load()
+	if errOut == nil {
+		return out
+	}
+	splitLevel := slog.LevelWarn
+	if h.opts.SplitLevel != nil {
+		splitLevel = h.opts.SplitLevel.Level()
+	}
+	if level >= splitLevel {
+		return errOut
+	}
+	return out
+}
+
+// levelOutput returns the output of the writer returned by WriterFor
+// for the provided level, or nil if WriterFor returned nil.
+func (h *CLIHandler) levelOutput(level slog.Level) *output {
+	if outs := h.state.levelOuts.Load(); outs != nil {
+		if out, ok := (*outs)[level]; ok {
+			return out
+		}
+	}
+
+	w := h.opts.WriterFor(level)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	outs := h.state.levelOuts.Load()
+	if outs != nil {
+		if out, ok := (*outs)[level]; ok {
+			return out
+		}
+	}
+	var out *output
+	if w != nil {
+		out = newOutput(w, h.state.color)
+	}
+	// The map is copied, so it can be read without holding h.mu.
+	m := make(map[slog.Level]*output)
+	if outs != nil {
+		maps.Copy(m, *outs)
+	}
+	m[level] = out
+	h.state.levelOuts.Store(&m)
+	return out
+}
+
+// updateLevelOutputs replaces the outputs returned by levelOutput
+// with the result of calling f with each of them. h.mu must be held.
+func (h *CLIHandler) updateLevelOutputs(f func(out *output) *output) {
+	outs := h.state.levelOuts.Load()
+	if outs == nil {
+		return
+	}
+	m := make(map[slog.Level]*output, len(*outs))
+	for level, out := range *outs {
+		if out != nil {
+			out = f(out)
+		}
+		m[level] = out
+	}
+	h.state.levelOuts.Store(&m)
+}
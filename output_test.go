@@ -0,0 +1,142 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"testing"
+)
+
+func TestNewCLIHandlerSplit(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       *HandlerOptions
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "default",
+			opts:       &HandlerOptions{OmitTime: true, Level: slog.LevelDebug},
+			wantStdout: "DEBUG debug g.a=1\nINFO info g.a=1\n",
+			wantStderr: "WARN warn g.a=1\nERROR error g.a=1\n",
+		},
+		{
+			name: "SplitLevel",
+			opts: &HandlerOptions{
+				OmitTime:   true,
+				Level:      slog.LevelDebug,
+				SplitLevel: slog.LevelError,
+			},
+			wantStdout: "DEBUG debug g.a=1\nINFO info g.a=1\nWARN warn g.a=1\n",
+			wantStderr: "ERROR error g.a=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			h := NewCLIHandlerSplit(&stdout, &stderr, tt.opts)
+			logger := slog.New(h).WithGroup("g").With("a", 1)
+
+			ctx := context.Background()
+			logger.DebugContext(ctx, "debug")
+			logger.InfoContext(ctx, "info")
+			logger.WarnContext(ctx, "warn")
+			logger.ErrorContext(ctx, "error")
+
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("unexpected stdout:\ngot:\n%s\nwant:\n%s", got, tt.wantStdout)
+			}
+			if got := stderr.String(); got != tt.wantStderr {
+				t.Errorf("unexpected stderr:\ngot:\n%s\nwant:\n%s", got, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestNewCLIHandlerSplit_color(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	h := NewCLIHandlerSplit(&stdout, &stderr, &HandlerOptions{
+		OmitTime: true,
+		Theme:    &Theme{Warn: "<warn>", ErrorAttr: "<error>"},
+	})
+	h.state.errOut.Load().color = true
+	logger := slog.New(h).With("err", errors.New("failure"))
+
+	logger.Info("info")
+	logger.Warn("warn")
+
+	if got, want := stdout.String(), "INFO info err=failure\n"; got != want {
+		t.Errorf("unexpected stdout:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := stderr.String(), "<warn>WARN\x1b[0m warn <error>err=failure\x1b[0m\n"; got != want {
+		t.Errorf("unexpected stderr:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestCLIHandler_WriterFor(t *testing.T) {
+	var out, debug bytes.Buffer
+
+	calls := make(map[slog.Level]int)
+	h := NewCLIHandler(&out, &HandlerOptions{
+		Level:    slog.LevelDebug,
+		OmitTime: true,
+		WriterFor: func(level slog.Level) io.Writer {
+			calls[level]++
+			if level < slog.LevelInfo {
+				return &debug
+			}
+			return nil
+		},
+	})
+	logger := slog.New(h)
+
+	logger.Debug("first")
+	logger.Info("second")
+	logger.With("a", 1).Debug("third")
+	logger.WithGroup("g").Info("fourth")
+
+	if got, want := debug.String(), "DEBUG first\nDEBUG third a=1\n"; got != want {
+		t.Errorf("unexpected debug output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := out.String(), "INFO second\nINFO fourth\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if want := map[slog.Level]int{slog.LevelDebug: 1, slog.LevelInfo: 1}; !maps.Equal(calls, want) {
+		t.Errorf("unexpected calls: got: %v, want: %v", calls, want)
+	}
+}
+
+func TestCLIHandler_WriterFor_group(t *testing.T) {
+	var out, info bytes.Buffer
+
+	h := NewCLIHandler(&out, &HandlerOptions{
+		Format:   FormatGitHub,
+		OmitTime: true,
+		WriterFor: func(level slog.Level) io.Writer {
+			if level == slog.LevelInfo {
+				return &info
+			}
+			return nil
+		},
+	})
+	if err := h.StartGroup("Build"); err != nil {
+		t.Fatalf("start group error: %v", err)
+	}
+	slog.New(h).Info("message")
+	if err := h.EndGroup(); err != nil {
+		t.Fatalf("end group error: %v", err)
+	}
+
+	if got, want := info.String(), "::group::Build\nINFO message\n::endgroup::\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if out.Len() != 0 {
+		t.Errorf("unexpected default output: %q", out.String())
+	}
+}
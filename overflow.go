@@ -0,0 +1,199 @@
+package clilog
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// OverflowMode controls how a [CLIHandler] renders lines wider than
+// the output.
+type OverflowMode int
+
+// Overflow modes.
+const (
+	// OverflowNone writes long lines unmodified. Terminals usually
+	// wrap them at the last column.
+	OverflowNone OverflowMode = iota
+
+	// OverflowWrap moves the attributes that do not fit in the
+	// line to continuation lines, indented under the message.
+	OverflowWrap
+
+	// OverflowTruncate truncates long lines, replacing the last
+	// visible character with "…".
+	OverflowTruncate
+)
+
+// String returns a name for the overflow mode.
+func (m OverflowMode) String() string {
+	switch m {
+	case OverflowNone:
+		return "none"
+	case OverflowWrap:
+		return "wrap"
+	case OverflowTruncate:
+		return "truncate"
+	default:
+		return fmt.Sprintf("OverflowMode(%d)", int(m))
+	}
+}
+
+// ellipsis is appended to truncated lines.
+const ellipsis = "…"
+
+// lineWidth returns the maximum width of the lines written to out. It
+// returns 0 if the width is unknown.
+func (h *CLIHandler) lineWidth(out *output) int {
+	if h.opts.Width > 0 {
+		return h.opts.Width
+	}
+	if !out.tty {
+		return 0
+	}
+	return terminalWidth(out.w)
+}
+
+// fitLine applies the configured overflow mode to the log line in
+// buf.
+func (h *CLIHandler) fitLine(buf *attrBuffer, out *output) {
+	width := h.lineWidth(out)
+	if width <= 0 {
+		return
+	}
+	switch h.opts.Overflow {
+	case OverflowWrap:
+		h.wrapLine(buf, width)
+	case OverflowTruncate:
+		truncateLine(buf.line, width)
+	}
+}
+
+// wrapLine moves the attributes in buf that exceed width to
+// continuation lines, indented under the message.
+func (h *CLIHandler) wrapLine(buf *attrBuffer, width int) {
+	line := *buf.line
+	if len(buf.cuts) == 0 || visibleWidth(line) <= width {
+		return
+	}
+
+	b := newBuffer()
+	defer b.free()
+
+	indent := visibleWidth(line[:buf.msg])
+	sep := len(h.opts.AttrSeparator)
+	sepWidth := visibleWidth([]byte(h.opts.AttrSeparator))
+
+	b.Write(line[:buf.cuts[0]])
+	col := visibleWidth(*b)
+	for i, start := range buf.cuts {
+		end := len(line)
+		if i+1 < len(buf.cuts) {
+			end = buf.cuts[i+1]
+		}
+		attr := line[start:end]
+		w := visibleWidth(attr)
+		if col+w <= width || col <= indent {
+			b.Write(attr)
+			col += w
+			continue
+		}
+		b.WriteByte('\n')
+		for j := 0; j < indent; j++ {
+			b.WriteByte(' ')
+		}
+		b.Write(attr[sep:])
+		col = indent + w - sepWidth
+	}
+	*buf.line = append(line[:0], *b...)
+}
+
+// truncateLine truncates the line in b to width terminal cells.
+// The styles and hyperlinks open at the truncation point are closed.
+func truncateLine(b *buffer, width int) {
+	line := *b
+	if visibleWidth(line) <= width {
+		return
+	}
+
+	var col, i int
+	styled, linked := false, false
+	for i < len(line) {
+		if n := ansiLen(line[i:]); n > 0 {
+			if seq := line[i : i+n]; bytes.HasPrefix(seq, []byte("\x1b]8;")) {
+				linked = !bytes.HasSuffix(seq, []byte(";\x1b\\"))
+			} else {
+				styled = true
+			}
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRune(line[i:])
+		w := runeWidth(r)
+		if col+w > width-1 {
+			break
+		}
+		i += size
+		col += w
+	}
+
+	*b = line[:i]
+	b.WriteString(ellipsis)
+	if linked {
+		b.WriteString("\x1b]8;;\x1b\\")
+	}
+	if styled {
+		b.WriteString(ansiReset)
+	}
+}
+
+// visibleWidth returns the number of terminal cells used to display
+// s, ignoring ANSI escape sequences (see runeWidth).
+func visibleWidth(s []byte) int {
+	var n int
+	for len(s) > 0 {
+		if l := ansiLen(s); l > 0 {
+			s = s[l:]
+			continue
+		}
+		r, size := utf8.DecodeRune(s)
+		s = s[size:]
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// ansiLen returns the length of the ANSI escape sequence at the
+// beginning of s. It supports CSI sequences (e.g. SGR styles) and OSC
+// sequences (e.g. OSC 8 hyperlinks). Any other ESC followed by a byte
+// in the range 0x40–0x7E is a two-byte sequence, while an ESC followed
+// by anything else is considered on its own. It returns 0 if s does
+// not start with an escape sequence.
+func ansiLen(s []byte) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			switch {
+			case s[i] == '\a':
+				return i + 1
+			case s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\':
+				return i + 2
+			}
+		}
+	default:
+		if s[1] >= 0x40 && s[1] <= 0x7e {
+			return 2
+		}
+		return 1
+	}
+	return len(s)
+}
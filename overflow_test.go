@@ -0,0 +1,174 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestCLIHandler_Overflow(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *HandlerOptions
+		with  func(*slog.Logger) *slog.Logger
+		attrs []any
+		want  string
+	}{
+		{
+			name:  "wrap",
+			opts:  &HandlerOptions{Overflow: OverflowWrap, Width: 30},
+			attrs: []any{"a", "1", "bbbbbb", "2", "c", "3", "d", "4444444444"},
+			want: "INFO message a=1 bbbbbb=2 c=3\n" +
+				"     d=4444444444\n",
+		},
+		{
+			name: "wrap preformatted",
+			opts: &HandlerOptions{Overflow: OverflowWrap, Width: 22},
+			with: func(l *slog.Logger) *slog.Logger {
+				return l.With("a", "1", "bbbbbb", "2")
+			},
+			attrs: []any{"c", "3", "dddddddddddddddddddd", "4"},
+			want: "INFO message a=1\n" +
+				"     bbbbbb=2 c=3\n" +
+				"     dddddddddddddddddddd=4\n",
+		},
+		{
+			name:  "wrap fits",
+			opts:  &HandlerOptions{Overflow: OverflowWrap, Width: 80},
+			attrs: []any{"a", "1", "b", "2"},
+			want:  "INFO message a=1 b=2\n",
+		},
+		{
+			name:  "wrap MessageFirst",
+			opts:  &HandlerOptions{Overflow: OverflowWrap, Width: 20, Layout: MessageFirst, AttrSeparator: "  "},
+			attrs: []any{"a", "1", "b", "2"},
+			want: "INFO message  a=1\n" +
+				"     b=2\n",
+		},
+		{
+			name:  "truncate",
+			opts:  &HandlerOptions{Overflow: OverflowTruncate, Width: 19},
+			attrs: []any{"a", "1", "b", "2"},
+			want:  "INFO message a=1 b…\n",
+		},
+		{
+			name:  "truncate colors",
+			opts:  &HandlerOptions{Overflow: OverflowTruncate, Width: 10, Color: ColorAlways},
+			attrs: []any{"a", "1"},
+			want:  "\x1b[34mINFO\x1b[0m mess…\x1b[0m\n",
+		},
+		{
+			name:  "no width",
+			opts:  &HandlerOptions{Overflow: OverflowTruncate},
+			attrs: []any{"a", "1", "b", "2"},
+			want:  "INFO message a=1 b=2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.OmitTime = true
+
+			var buf bytes.Buffer
+			logger := slog.New(NewCLIHandler(&buf, tt.opts))
+			if tt.with != nil {
+				logger = tt.with(logger)
+			}
+			logger.Info("message", tt.attrs...)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  string
+	}{
+		{
+			name:  "short",
+			line:  "hello",
+			width: 5,
+			want:  "hello",
+		},
+		{
+			name:  "long",
+			line:  "hello world",
+			width: 5,
+			want:  "hell…",
+		},
+		{
+			name:  "multi-byte",
+			line:  "ñandú ñandú",
+			width: 6,
+			want:  "ñandú…",
+		},
+		{
+			name:  "wide",
+			line:  "日本語のログ",
+			width: 6,
+			want:  "日本…",
+		},
+		{
+			name:  "wide boundary",
+			line:  "a日本語",
+			width: 5,
+			want:  "a日…",
+		},
+		{
+			name:  "combining",
+			line:  "cafe\u0301 cafe\u0301",
+			width: 5,
+			want:  "cafe\u0301…",
+		},
+		{
+			name:  "hyperlink",
+			line:  hyperlink("file:///main.go", "main.go:12") + " message",
+			width: 5,
+			want:  "\x1b]8;;file:///main.go\x1b\\main…\x1b]8;;\x1b\\",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := buffer(tt.line)
+			truncateLine(&b, tt.width)
+			if got := string(b); got != tt.want {
+				t.Errorf("unexpected line: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{"plain", "hello", 5},
+		{"styled", ansiRed + "hello" + ansiReset, 5},
+		{"hyperlink", hyperlink("file:///main.go", "main.go"), 7},
+		{"multi-byte", "ñandú", 5},
+		{"wide", "日本語", 6},
+		{"emoji", "ok 👍", 5},
+		{"combining", "cafe\u0301", 4},
+		{"zero width joiner", "a\u200db", 2},
+		{"unterminated", "hi\x1b[3", 2},
+		{"two-byte sequence", "a\x1bMb", 2},
+		{"lone escape", "x\x1b\ny", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := visibleWidth([]byte(tt.s)); got != tt.want {
+				t.Errorf("unexpected width: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !plan9 && !windows
+
+package clilog
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isBrokenPipe reports whether err is caused by writing to a pipe
+// whose reader exited.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
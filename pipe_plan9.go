@@ -0,0 +1,12 @@
+package clilog
+
+import (
+	"strings"
+)
+
+// isBrokenPipe reports whether err is caused by writing to a pipe
+// whose reader exited. On Plan 9, these writes fail with an "i/o on
+// hungup channel" error.
+func isBrokenPipe(err error) bool {
+	return strings.Contains(err.Error(), "hungup channel")
+}
@@ -0,0 +1,78 @@
+//go:build !plan9
+
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestCLIHandler_StopOnBrokenPipe(t *testing.T) {
+	var errs []error
+	h := NewCLIHandler(errWriter{fmt.Errorf("write: %w", syscall.EPIPE)}, &HandlerOptions{
+		StopOnBrokenPipe: true,
+		OnError:          func(err error) { errs = append(errs, err) },
+	})
+	logger := slog.New(h).With("a", 1)
+
+	for i := 0; i < 3; i++ {
+		if err := logger.Handler().Handle(context.Background(), slog.NewRecord(testTime, slog.LevelInfo, "message", 0)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], syscall.EPIPE) {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestCLIHandler_StopOnBrokenPipe_other(t *testing.T) {
+	h := NewCLIHandler(errWriter{errors.New("disk full")}, &HandlerOptions{StopOnBrokenPipe: true})
+	logger := slog.New(h)
+
+	logger.Info("first")
+	logger.Info("second")
+
+	if got := h.Errors(); got != 2 {
+		t.Errorf("unexpected number of errors: got: %v, want: 2", got)
+	}
+}
+
+func TestCLIHandler_StopOnBrokenPipe_Fallback(t *testing.T) {
+	var fallback bytes.Buffer
+
+	h := NewCLIHandler(errWriter{syscall.EPIPE}, &HandlerOptions{
+		OmitTime:         true,
+		StopOnBrokenPipe: true,
+		Fallback:         &fallback,
+	})
+	slog.New(h).Info("message")
+
+	if got, want := fallback.String(), "INFO message\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestCLIHandler_StopOnBrokenPipe_pipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("could not create pipe: %v", err)
+	}
+	defer w.Close()
+	r.Close()
+
+	h := NewCLIHandler(w, &HandlerOptions{StopOnBrokenPipe: true})
+	logger := slog.New(h)
+	logger.Info("first")
+	logger.Info("second")
+
+	if got := h.Errors(); got != 1 {
+		t.Errorf("unexpected number of errors: got: %v, want: 1", got)
+	}
+}
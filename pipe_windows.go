@@ -0,0 +1,16 @@
+package clilog
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errNoData is the error returned by Windows when writing to a pipe
+// that is being closed (ERROR_NO_DATA).
+const errNoData syscall.Errno = 232
+
+// isBrokenPipe reports whether err is caused by writing to a pipe
+// whose reader exited.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.ERROR_BROKEN_PIPE) || errors.Is(err, errNoData) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,93 @@
+package clilog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TimePrecision is the precision of the timestamps rendered by a
+// [CLIHandler].
+type TimePrecision int
+
+// Timestamp precisions.
+const (
+	// PrecisionLayout renders timestamps with the precision of the
+	// configured layout.
+	PrecisionLayout TimePrecision = iota
+
+	// PrecisionSeconds renders timestamps with a precision of
+	// seconds.
+	PrecisionSeconds
+
+	// PrecisionMilliseconds renders timestamps with a precision of
+	// milliseconds.
+	PrecisionMilliseconds
+
+	// PrecisionMicroseconds renders timestamps with a precision of
+	// microseconds.
+	PrecisionMicroseconds
+)
+
+// String returns a name for the timestamp precision.
+func (p TimePrecision) String() string {
+	switch p {
+	case PrecisionLayout:
+		return "layout"
+	case PrecisionSeconds:
+		return "seconds"
+	case PrecisionMilliseconds:
+		return "milliseconds"
+	case PrecisionMicroseconds:
+		return "microseconds"
+	default:
+		return fmt.Sprintf("TimePrecision(%d)", int(p))
+	}
+}
+
+// digits returns the number of fractional digits of the seconds
+// rendered with the precision p. It returns -1 for PrecisionLayout.
+func (p TimePrecision) digits() int {
+	switch p {
+	case PrecisionSeconds:
+		return 0
+	case PrecisionMilliseconds:
+		return 3
+	case PrecisionMicroseconds:
+		return 6
+	default:
+		return -1
+	}
+}
+
+// layoutPrecision returns layout modified to render the fractional
+// seconds with the precision p. The fractional seconds, if any, that
+// follow the seconds element "05" of layout are replaced. Layouts
+// without seconds are returned unmodified.
+func layoutPrecision(layout string, p TimePrecision) string {
+	digits := p.digits()
+	if digits < 0 {
+		return layout
+	}
+
+	i := strings.Index(layout, "05")
+	if i < 0 {
+		return layout
+	}
+	start := i + 2
+	end := start
+	sep, digit := byte('.'), byte('0')
+	if start+1 < len(layout) && (layout[start] == '.' || layout[start] == ',') &&
+		(layout[start+1] == '0' || layout[start+1] == '9') {
+		sep, digit = layout[start], layout[start+1]
+		end = start + 2
+		for end < len(layout) && layout[end] == digit {
+			end++
+		}
+	}
+
+	var frac string
+	if digits > 0 {
+		frac = string(sep) + strings.Repeat(string(digit), digits)
+	}
+	return layout[:start] + frac + layout[end:]
+}
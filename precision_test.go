@@ -0,0 +1,93 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestLayoutPrecision(t *testing.T) {
+	tests := []struct {
+		name      string
+		layout    string
+		precision TimePrecision
+		want      string
+	}{
+		{
+			name:      "layout",
+			layout:    time.RFC3339,
+			precision: PrecisionLayout,
+			want:      time.RFC3339,
+		},
+		{
+			name:      "add milliseconds",
+			layout:    time.RFC3339,
+			precision: PrecisionMilliseconds,
+			want:      "2006-01-02T15:04:05.000Z07:00",
+		},
+		{
+			name:      "add microseconds",
+			layout:    time.TimeOnly,
+			precision: PrecisionMicroseconds,
+			want:      "15:04:05.000000",
+		},
+		{
+			name:      "replace fraction",
+			layout:    time.RFC3339Nano,
+			precision: PrecisionMilliseconds,
+			want:      "2006-01-02T15:04:05.999Z07:00",
+		},
+		{
+			name:      "comma",
+			layout:    "15:04:05,000000",
+			precision: PrecisionMilliseconds,
+			want:      "15:04:05,000",
+		},
+		{
+			name:      "remove fraction",
+			layout:    time.StampMilli,
+			precision: PrecisionSeconds,
+			want:      time.Stamp,
+		},
+		{
+			name:      "no seconds",
+			layout:    time.Kitchen,
+			precision: PrecisionMilliseconds,
+			want:      time.Kitchen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := layoutPrecision(tt.layout, tt.precision); got != tt.want {
+				t.Errorf("unexpected layout: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_TimePrecision(t *testing.T) {
+	var buf bytes.Buffer
+
+	now := testTime.Add(123456789 * time.Nanosecond)
+	for _, opts := range []*HandlerOptions{
+		{TimeFormat: time.TimeOnly, TimePrecision: PrecisionMilliseconds},
+		{TimeFormat: time.RFC3339Nano, TimePrecision: PrecisionMicroseconds},
+		{TimeFormat: TimeUnixMilli, TimePrecision: PrecisionSeconds},
+		{TimeFormat: TimeElapsed, TimePrecision: PrecisionMicroseconds},
+	} {
+		opts.Now = func() time.Time { return now }
+		h := NewCLIHandler(&buf, opts)
+		h.start = testTime
+		slog.New(h).Info("message")
+	}
+
+	want := "12:24:43.123 INFO message\n" +
+		"2023-09-20T12:24:43.123456Z INFO message\n" +
+		"1695212683123 INFO message\n" +
+		"+0.123457s INFO message\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithPrefix returns a handler that prepends prefix in brackets to
+// the message of every record passed to h (e.g. "[build] message").
+// Prefixes are nestable: the prefixes added by successive calls are
+// rendered in order (e.g. "[build] [test] message"). If h is a
+// [CLIHandler], the returned handler is a CLIHandler that shares its
+// output with h. If prefix is empty, WithPrefix returns h.
+func WithPrefix(h slog.Handler, prefix string) slog.Handler {
+	if prefix == "" {
+		return h
+	}
+	if ch, ok := h.(*CLIHandler); ok {
+		h2 := ch.clone()
+		h2.prefix += bracketPrefix(prefix)
+		return h2
+	}
+	if ph, ok := h.(*prefixHandler); ok {
+		return &prefixHandler{h: ph.h, prefix: ph.prefix + bracketPrefix(prefix)}
+	}
+	return &prefixHandler{h: h, prefix: bracketPrefix(prefix)}
+}
+
+// bracketPrefix returns the prefix of the messages rendered for the
+// provided tag.
+func bracketPrefix(prefix string) string {
+	return "[" + prefix + "] "
+}
+
+// prefixHandler is the [slog.Handler] returned by WithPrefix for
+// handlers other than CLIHandler.
+type prefixHandler struct {
+	h      slog.Handler
+	prefix string
+}
+
+// Enabled reports whether the wrapped handler handles records at the
+// given level.
+func (h *prefixHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle prepends the prefixes to the message of r and passes it to
+// the wrapped handler.
+func (h *prefixHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Message = h.prefix + r.Message
+	return h.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new prefixHandler whose wrapped handler is the
+// result of calling WithAttrs on the receiver's wrapped handler.
+func (h *prefixHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &prefixHandler{h: h.h.WithAttrs(attrs), prefix: h.prefix}
+}
+
+// WithGroup returns a new prefixHandler whose wrapped handler is the
+// result of calling WithGroup on the receiver's wrapped handler.
+func (h *prefixHandler) WithGroup(name string) slog.Handler {
+	return &prefixHandler{h: h.h.WithGroup(name), prefix: h.prefix}
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *prefixHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
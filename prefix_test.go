@@ -0,0 +1,80 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestWithPrefix(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *HandlerOptions
+		build func(slog.Handler) slog.Handler
+		want  string
+	}{
+		{
+			name: "Prefix",
+			opts: &HandlerOptions{OmitTime: true, Prefix: "tool"},
+			build: func(h slog.Handler) slog.Handler {
+				return h
+			},
+			want: "INFO [tool] message a=1\n",
+		},
+		{
+			name: "WithPrefix",
+			build: func(h slog.Handler) slog.Handler {
+				return WithPrefix(h, "build")
+			},
+			want: "INFO [build] message a=1\n",
+		},
+		{
+			name: "nested",
+			opts: &HandlerOptions{OmitTime: true, Prefix: "tool"},
+			build: func(h slog.Handler) slog.Handler {
+				return WithPrefix(WithPrefix(h, "build").WithAttrs([]slog.Attr{slog.Int("a", 1)}), "test")
+			},
+			want: "INFO [tool] [build] [test] message a=1 a=1\n",
+		},
+		{
+			name: "empty",
+			build: func(h slog.Handler) slog.Handler {
+				return WithPrefix(h, "")
+			},
+			want: "INFO message a=1\n",
+		},
+		{
+			name: "wrapped handler",
+			build: func(h slog.Handler) slog.Handler {
+				fh := FilterHandler(h, func(context.Context, slog.Record) bool { return true })
+				return WithPrefix(WithPrefix(fh, "build").WithGroup("g"), "test")
+			},
+			want: "INFO [build] [test] message g.a=1\n",
+		},
+		{
+			name: "logfmt",
+			opts: &HandlerOptions{OmitTime: true, Format: FormatLogfmt},
+			build: func(h slog.Handler) slog.Handler {
+				return WithPrefix(h, "build")
+			},
+			want: "level=INFO msg=\"[build] message\" a=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if opts == nil {
+				opts = &HandlerOptions{OmitTime: true}
+			}
+			var buf bytes.Buffer
+			logger := slog.New(tt.build(NewCLIHandler(&buf, opts)))
+			logger.Info("message", "a", 1)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
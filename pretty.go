@@ -0,0 +1,259 @@
+package clilog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrettyPrint reads the log lines written by a [slog.JSONHandler] or
+// in logfmt format (e.g. by a [slog.TextHandler]) from r and writes
+// them to w rendered by a [CLIHandler] created with the provided
+// options. The attributes keep their order, JSON objects are rendered
+// as groups and the levels are parsed with [ParseLevel], so they are
+// filtered according to [HandlerOptions.Level]. The source code
+// position, if any, is rendered as a regular attribute. Lines that are
+// neither JSON objects nor logfmt lines with a "level" or "msg" key
+// are written unmodified.
+func PrettyPrint(r io.Reader, w io.Writer, opts *HandlerOptions) error {
+	h := NewCLIHandler(w, opts)
+	ctx := context.Background()
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if perr := prettyPrintLine(ctx, h, w, line); perr != nil {
+				return perr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// prettyPrintLine renders line with h if it is a JSON object or a
+// logfmt line. Otherwise, it writes line to w unmodified.
+func prettyPrintLine(ctx context.Context, h *CLIHandler, w io.Writer, line []byte) error {
+	r, err := parseRecord(bytes.TrimSpace(line))
+	if err != nil {
+		if line[len(line)-1] != '\n' {
+			line = append(line, '\n')
+		}
+		_, err := w.Write(line)
+		return err
+	}
+	if !h.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.Handle(ctx, r)
+}
+
+// parseRecord parses a log line in JSON or logfmt format.
+func parseRecord(line []byte) (slog.Record, error) {
+	if len(line) > 0 && line[0] == '{' {
+		return parseJSONRecord(line)
+	}
+	return parseLogfmtRecord(line)
+}
+
+// parseJSONRecord parses the log line written by a slog.JSONHandler.
+func parseJSONRecord(line []byte) (slog.Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	v, err := jsonValue(dec)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	if dec.More() {
+		return slog.Record{}, errors.New("unexpected data after JSON object")
+	}
+	if v.Kind() != slog.KindGroup {
+		return slog.Record{}, errors.New("not a JSON object")
+	}
+	return newParsedRecord(v.Group()), nil
+}
+
+// parseLogfmtRecord parses a log line in logfmt format. Keys without
+// value are assumed to be true. The line must contain the key "level"
+// or "msg".
+func parseLogfmtRecord(line []byte) (slog.Record, error) {
+	var (
+		attrs []slog.Attr
+		found bool
+	)
+	s := string(line)
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			break
+		}
+		i := strings.IndexAny(s, "= ")
+		if i == 0 {
+			return slog.Record{}, errors.New("missing key")
+		}
+		if i < 0 || s[i] == ' ' {
+			if i < 0 {
+				i = len(s)
+			}
+			attrs = append(attrs, slog.Bool(s[:i], true))
+			s = s[i:]
+			continue
+		}
+		key, val := s[:i], s[i+1:]
+		if strings.HasPrefix(val, `"`) {
+			q, err := strconv.QuotedPrefix(val)
+			if err != nil {
+				return slog.Record{}, err
+			}
+			s = val[len(q):]
+			if val, err = strconv.Unquote(q); err != nil {
+				return slog.Record{}, err
+			}
+		} else {
+			j := strings.IndexByte(val, ' ')
+			if j < 0 {
+				j = len(val)
+			}
+			val, s = val[:j], val[j:]
+		}
+		if s != "" && s[0] != ' ' {
+			return slog.Record{}, errors.New("missing separator")
+		}
+		found = found || key == slog.LevelKey || key == slog.MessageKey
+		attrs = append(attrs, slog.String(key, val))
+	}
+	if !found {
+		return slog.Record{}, errors.New("not a logfmt line")
+	}
+	return newParsedRecord(attrs), nil
+}
+
+// newParsedRecord returns a new record built from the attributes of a
+// parsed log line. The time, level and message are taken from the
+// attributes with the corresponding built-in keys, if they are valid.
+func newParsedRecord(attrs []slog.Attr) slog.Record {
+	var (
+		r     slog.Record
+		other []slog.Attr
+	)
+	r.Level = slog.LevelInfo
+	for _, a := range attrs {
+		switch {
+		case a.Key == slog.TimeKey && a.Value.Kind() == slog.KindString:
+			t, err := time.Parse(time.RFC3339Nano, a.Value.String())
+			if err != nil {
+				other = append(other, a)
+				continue
+			}
+			r.Time = t
+		case a.Key == slog.LevelKey && a.Value.Kind() == slog.KindString:
+			level, err := ParseLevel(a.Value.String())
+			if err != nil {
+				other = append(other, a)
+				continue
+			}
+			r.Level = level
+		case a.Key == slog.MessageKey && a.Value.Kind() == slog.KindString:
+			r.Message = a.Value.String()
+		case a.Key == slog.SourceKey && a.Value.Kind() == slog.KindGroup:
+			other = append(other, slog.String(slog.SourceKey, jsonSource(a.Value)))
+		default:
+			other = append(other, a)
+		}
+	}
+	r.AddAttrs(other...)
+	return r
+}
+
+// jsonSource returns the source code position rendered by a
+// slog.JSONHandler in the format "file:line".
+func jsonSource(v slog.Value) string {
+	var (
+		file string
+		line int64
+	)
+	for _, a := range v.Group() {
+		switch a.Key {
+		case "file":
+			file = a.Value.String()
+		case "line":
+			if a.Value.Kind() == slog.KindInt64 {
+				line = a.Value.Int64()
+			}
+		}
+	}
+	return file + ":" + strconv.FormatInt(line, 10)
+}
+
+// jsonValue decodes the next JSON value read by dec. Objects are
+// returned as groups, keeping the order of their members.
+func jsonValue(dec *json.Decoder) (slog.Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			var attrs []slog.Attr
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return slog.Value{}, err
+				}
+				v, err := jsonValue(dec)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				attrs = append(attrs, slog.Attr{Key: key.(string), Value: v})
+			}
+			if _, err := dec.Token(); err != nil {
+				return slog.Value{}, err
+			}
+			return slog.GroupValue(attrs...), nil
+		case '[':
+			elems := []any{}
+			for dec.More() {
+				var e any
+				if err := dec.Decode(&e); err != nil {
+					return slog.Value{}, err
+				}
+				elems = append(elems, e)
+			}
+			if _, err := dec.Token(); err != nil {
+				return slog.Value{}, err
+			}
+			return slog.AnyValue(elems), nil
+		}
+	case string:
+		return slog.StringValue(t), nil
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return slog.Int64Value(i), nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return slog.Value{}, err
+		}
+		return slog.Float64Value(f), nil
+	case bool:
+		return slog.BoolValue(t), nil
+	case nil:
+		return slog.AnyValue(nil), nil
+	}
+	return slog.Value{}, fmt.Errorf("unexpected JSON token %v", tok)
+}
@@ -0,0 +1,157 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrettyPrint(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *HandlerOptions
+		input string
+		want  string
+	}{
+		{
+			name:  "attrs",
+			input: `{"time":"2023-09-20T12:24:43Z","level":"INFO","msg":"hello world","b":2,"a":"x y","f":1.5,"ok":true,"nil":null}` + "\n",
+			want:  "2023-09-20T12:24:43Z INFO hello world b=2 a=\"x y\" f=1.5 ok=true nil=<nil>\n",
+		},
+		{
+			name:  "groups",
+			opts:  &HandlerOptions{OmitTime: true},
+			input: `{"level":"WARN","msg":"message","g":{"h":{"a":1},"b":[1,"x"]}}`,
+			want:  "WARN message g.h.a=1 g.b=\"[1,\\\"x\\\"]\"\n",
+		},
+		{
+			name:  "levels",
+			opts:  &HandlerOptions{OmitTime: true, Level: LevelTrace},
+			input: "{\"level\":\"DEBUG-4\",\"msg\":\"trace\"}\n{\"level\":\"INFO+2\",\"msg\":\"notice\"}\n{\"level\":\"ERROR+4\",\"msg\":\"fatal\"}\n",
+			want:  "TRACE trace\nNOTICE notice\nFATAL fatal\n",
+		},
+		{
+			name:  "level filter",
+			opts:  &HandlerOptions{OmitTime: true, Level: slog.LevelWarn},
+			input: "{\"level\":\"INFO\",\"msg\":\"info\"}\n{\"level\":\"ERROR\",\"msg\":\"error\"}\n",
+			want:  "ERROR error\n",
+		},
+		{
+			name:  "source",
+			opts:  &HandlerOptions{OmitTime: true},
+			input: `{"level":"INFO","source":{"function":"main.main","file":"/src/main.go","line":12},"msg":"message"}`,
+			want:  "INFO message source=/src/main.go:12\n",
+		},
+		{
+			name:  "invalid level",
+			opts:  &HandlerOptions{OmitTime: true},
+			input: `{"level":"LOUD","msg":"message"}`,
+			want:  "INFO message level=LOUD\n",
+		},
+		{
+			name:  "logfmt",
+			input: "time=2023-09-20T12:24:43.5Z level=WARN msg=\"hello world\" a=1 g.b=\"x y\" verbose\n",
+			want:  "2023-09-20T12:24:43Z WARN hello world a=1 g.b=\"x y\" verbose=true\n",
+		},
+		{
+			name:  "logfmt level filter",
+			opts:  &HandlerOptions{OmitTime: true, Level: slog.LevelWarn},
+			input: "level=INFO msg=info\nlevel=ERROR msg=error\n",
+			want:  "ERROR error\n",
+		},
+		{
+			name:  "malformed logfmt",
+			opts:  &HandlerOptions{OmitTime: true},
+			input: "msg=\"unterminated\n=value msg=x\n",
+			want:  "msg=\"unterminated\n=value msg=x\n",
+		},
+		{
+			name:  "not JSON",
+			opts:  &HandlerOptions{OmitTime: true},
+			input: "panic: oops\n{\"msg\":\"message\"}\n{broken\n",
+			want:  "panic: oops\nINFO message\n{broken\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := PrettyPrint(strings.NewReader(tt.input), &buf, tt.opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyPrint_roundTrip(t *testing.T) {
+	var jsonBuf, want bytes.Buffer
+	jsonLogger := slog.New(slog.NewJSONHandler(&jsonBuf, &slog.HandlerOptions{Level: LevelTrace}))
+	cliHandler := NewCLIHandler(&want, &HandlerOptions{Level: LevelTrace})
+
+	tm := time.Date(2023, 9, 20, 12, 24, 43, 0, time.UTC)
+	records := []slog.Record{
+		slog.NewRecord(tm, LevelTrace, "trace", 0),
+		slog.NewRecord(tm, slog.LevelWarn, "warning", 0),
+	}
+	records[1].AddAttrs(slog.String("path", "/tmp"), slog.Group("req", slog.Int("id", 7)))
+
+	ctx := context.Background()
+	for _, r := range records {
+		jsonLogger.Handler().Handle(ctx, r)
+		cliHandler.Handle(ctx, r)
+	}
+
+	var got bytes.Buffer
+	if err := PrettyPrint(&jsonBuf, &got, &HandlerOptions{Level: LevelTrace}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got.String(), want.String())
+	}
+}
+
+func TestPrettyPrint_roundTripText(t *testing.T) {
+	var textBuf, want bytes.Buffer
+	textHandler := slog.NewTextHandler(&textBuf, nil)
+	cliHandler := NewCLIHandler(&want, nil)
+
+	tm := time.Date(2023, 9, 20, 12, 24, 43, 0, time.UTC)
+	r := slog.NewRecord(tm, slog.LevelError, "request failed", 0)
+	r.AddAttrs(slog.String("path", "/tmp/a b"), slog.Group("req", slog.String("id", "7")))
+
+	ctx := context.Background()
+	textHandler.Handle(ctx, r)
+	cliHandler.Handle(ctx, r)
+
+	var got bytes.Buffer
+	if err := PrettyPrint(&textBuf, &got, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got.String(), want.String())
+	}
+}
+
+func TestPrettyPrint_error(t *testing.T) {
+	errRead := errors.New("read error")
+	r := &errReader{err: errRead}
+	if err := PrettyPrint(r, &bytes.Buffer{}, nil); !errors.Is(err, errRead) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// errReader is an [io.Reader] that always fails.
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,63 @@
+package clilog
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Keys of the attributes returned by [ProgramAttrs] and logged by
+// [Banner].
+const (
+	AppKey      = "app"
+	VersionKey  = "version"
+	HostKey     = "host"
+	PIDKey      = "pid"
+	GoKey       = "go"
+	PlatformKey = "platform"
+)
+
+// readBuildInfo returns the build information of the program. It
+// is a variable, so it can be replaced in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// ProgramAttrs returns attributes describing the running program:
+// its name ("app"), the version of its main module according to its
+// build information ("version"), the hostname ("host") and the
+// process ID ("pid"). The attributes whose value cannot be determined
+// are omitted.
+//
+// To include them in every record, pass them to the WithAttrs method
+// of the handler. To log them once, use [Banner].
+func ProgramAttrs() []slog.Attr {
+	attrs := []slog.Attr{slog.String(AppKey, programName())}
+	if bi, ok := readBuildInfo(); ok && bi.Main.Version != "" {
+		attrs = append(attrs, slog.String(VersionKey, bi.Main.Version))
+	}
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, slog.String(HostKey, host))
+	}
+	return append(attrs, slog.Int(PIDKey, os.Getpid()))
+}
+
+// Banner logs at LevelInfo a record with the message "starting", the
+// attributes returned by [ProgramAttrs], the Go version ("go") and
+// the operating system and architecture ("platform"). It is intended
+// to be called at startup, so logs pasted into bug reports include
+// the details of the environment.
+func Banner(logger *slog.Logger) {
+	attrs := append(ProgramAttrs(),
+		slog.String(GoKey, runtime.Version()),
+		slog.String(PlatformKey, runtime.GOOS+"/"+runtime.GOARCH),
+	)
+	logCaller(logger, 0, slog.LevelInfo, "starting", attrs)
+}
+
+// programName returns the name of the running program.
+func programName() string {
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, ".exe")
+}
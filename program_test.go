@@ -0,0 +1,68 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestProgramAttrs(t *testing.T) {
+	defer func(f func() (*debug.BuildInfo, bool)) { readBuildInfo = f }(readBuildInfo)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Main: debug.Module{Path: "example.com/mytool", Version: "v1.4.2"}}, true
+	}
+
+	got := make(map[string]slog.Value)
+	for _, a := range ProgramAttrs() {
+		got[a.Key] = a.Value
+	}
+
+	if v := got[AppKey].String(); v == "" {
+		t.Error("missing app")
+	}
+	if v := got[VersionKey].String(); v != "v1.4.2" {
+		t.Errorf("unexpected version: %v", v)
+	}
+	if host, err := os.Hostname(); err == nil && got[HostKey].String() != host {
+		t.Errorf("unexpected host: %v", got[HostKey])
+	}
+	if v := got[PIDKey].Int64(); v != int64(os.Getpid()) {
+		t.Errorf("unexpected pid: %v", v)
+	}
+}
+
+func TestProgramAttrs_noBuildInfo(t *testing.T) {
+	defer func(f func() (*debug.BuildInfo, bool)) { readBuildInfo = f }(readBuildInfo)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return nil, false
+	}
+
+	for _, a := range ProgramAttrs() {
+		if a.Key == VersionKey {
+			t.Errorf("unexpected version: %v", a.Value)
+		}
+	}
+}
+
+func TestBanner(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}))
+	Banner(logger)
+
+	got := buf.String()
+	for _, want := range []string{
+		"INFO starting app=",
+		" pid=" + strconv.Itoa(os.Getpid()),
+		" go=" + runtime.Version(),
+		" platform=" + runtime.GOOS + "/" + runtime.GOARCH + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %q does not contain %q", got, want)
+		}
+	}
+}
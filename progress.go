@@ -0,0 +1,132 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ProgressKey is the key of the attribute returned by [Progress].
+const ProgressKey = "progress"
+
+// DefaultProgressInterval is the minimum interval between the lines
+// written for the progress records with the same message when
+// [HandlerOptions.ProgressInterval] is not positive.
+const DefaultProgressInterval = 5 * time.Second
+
+// Progress returns an attribute that marks a record as a progress
+// update of the task described by its message. The fraction of the
+// task that is complete, between 0 and 1, is rendered as a percentage
+// (e.g. "progress=42%"). Fractions out of range are clamped, and NaN
+// is rendered as 0%.
+//
+// When the output of a [CLIHandler] is a terminal, the progress
+// records are rendered in place: each one replaces the previous
+// progress line, which is cleared when another record is written. If
+// the output is a [Console], the progress lines are rendered as its
+// status line. When the fraction reaches 1, the line is written as a
+// regular one. If the output is not a terminal, the progress records
+// with the same message are written at most once per
+// [HandlerOptions.ProgressInterval], except the first and the last
+// ones.
+func Progress(fraction float64) slog.Attr {
+	if math.IsNaN(fraction) {
+		fraction = 0
+	}
+	return slog.Any(ProgressKey, progress(min(max(fraction, 0), 1)))
+}
+
+// progress is the value of the attribute returned by Progress.
+type progress float64
+
+// LogValue renders the progress as a percentage.
+func (p progress) LogValue() slog.Value {
+	return slog.StringValue(strconv.FormatFloat(float64(p)*100, 'f', 0, 64) + "%")
+}
+
+// progressState is the state of the progress lines of a handler and
+// the handlers derived from it.
+type progressState struct {
+	inPlace bool                 // whether a progress line is displayed in place
+	last    map[string]time.Time // time of the last line written per message
+}
+
+// progressOf returns the progress of r, if it contains an attribute
+// created by Progress.
+func progressOf(r slog.Record) (float64, bool) {
+	var (
+		p  float64
+		ok bool
+	)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key != ProgressKey || a.Value.Kind() != slog.KindLogValuer {
+			return true
+		}
+		v, isProgress := a.Value.LogValuer().(progress)
+		p, ok = float64(v), isProgress
+		return !ok
+	})
+	return p, ok
+}
+
+// writeProgress writes the progress record r, rendered in b, to out.
+// The progress is p.
+func (h *CLIHandler) writeProgress(out *output, r slog.Record, p float64, b []byte) error {
+	done := p >= 1
+	line, _, _ := bytes.Cut(b, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+
+	if c, ok := out.w.(*Console); ok && c.IsTerminal() {
+		if done {
+			if err := h.reportError(c.ClearStatus()); err != nil {
+				return err
+			}
+			return h.write(out, b)
+		}
+		return h.reportError(c.SetStatus(string(line)))
+	}
+
+	if out.tty {
+		if done {
+			return h.write(out, b)
+		}
+		return h.writeLine(out, line, true)
+	}
+
+	if !h.progressDue(r, done) {
+		return nil
+	}
+	return h.write(out, b)
+}
+
+// progressDue reports whether the progress record r must be written
+// to an output that is not a terminal.
+func (h *CLIHandler) progressDue(r slog.Record, done bool) bool {
+	t := r.Time
+	if t.IsZero() {
+		t = h.now()
+	}
+	interval := h.opts.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ps := &h.state.progress
+	if done {
+		delete(ps.last, r.Message)
+		return true
+	}
+	if last, ok := ps.last[r.Message]; ok && t.Sub(last) < interval {
+		return false
+	}
+	if ps.last == nil {
+		ps.last = make(map[string]time.Time)
+	}
+	ps.last[r.Message] = t
+	return true
+}
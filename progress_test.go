@@ -0,0 +1,112 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		want     string
+	}{
+		{0, "0%"},
+		{0.424, "42%"},
+		{1, "100%"},
+		{-1, "0%"},
+		{2, "100%"},
+		{math.NaN(), "0%"},
+		{math.Inf(1), "100%"},
+	}
+
+	for _, tt := range tests {
+		a := Progress(tt.fraction)
+		if a.Key != ProgressKey {
+			t.Errorf("unexpected key: %v", a.Key)
+		}
+		if got := a.Value.Resolve().String(); got != tt.want {
+			t.Errorf("unexpected value for %v: got: %v, want: %v", tt.fraction, got, tt.want)
+		}
+	}
+}
+
+func TestCLIHandler_progress_terminal(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true})
+	h.state.out.Store(&output{w: &buf, tty: true})
+	logger := slog.New(h)
+
+	logger.Info("downloading", Progress(0.1))
+	logger.Info("downloading", Progress(0.5))
+	logger.Warn("slow connection")
+	logger.Info("downloading", Progress(0.9))
+	logger.Info("downloading", Progress(1))
+	logger.Info("done")
+
+	want := "INFO downloading progress=10%" +
+		clearLine + "INFO downloading progress=50%" +
+		clearLine + "WARN slow connection\n" +
+		"INFO downloading progress=90%" +
+		clearLine + "INFO downloading progress=100%\n" +
+		"INFO done\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestCLIHandler_progress_console(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Console{w: &buf, tty: true}
+	logger := slog.New(NewCLIHandler(c, &HandlerOptions{OmitTime: true}))
+
+	logger.Info("downloading", Progress(0.5))
+	logger.Info("message")
+	logger.Info("downloading", Progress(1))
+
+	want := clearLine + "INFO downloading progress=50%" +
+		clearLine + "INFO message\nINFO downloading progress=50%" +
+		clearLine +
+		"INFO downloading progress=100%\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestCLIHandler_progress_plain(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, ProgressInterval: 10 * time.Second})
+
+	start := time.Date(2023, 9, 20, 12, 24, 43, 0, time.UTC)
+	for i, tt := range []struct {
+		offset time.Duration
+		msg    string
+		p      float64
+	}{
+		{0, "download", 0.1},
+		{time.Second, "download", 0.2},
+		{2 * time.Second, "extract", 0.5},
+		{11 * time.Second, "download", 0.6},
+		{12 * time.Second, "download", 0.7},
+		{13 * time.Second, "download", 1},
+		{14 * time.Second, "download", 0.1},
+	} {
+		r := slog.NewRecord(start.Add(tt.offset), slog.LevelInfo, tt.msg, 0)
+		r.AddAttrs(Progress(tt.p))
+		if err := h.Handle(context.Background(), r); err != nil {
+			t.Fatalf("record %d: unexpected error: %v", i, err)
+		}
+	}
+
+	want := "INFO download progress=10%\n" +
+		"INFO extract progress=50%\n" +
+		"INFO download progress=60%\n" +
+		"INFO download progress=100%\n" +
+		"INFO download progress=10%\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package clilog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QuoteMode controls how a [CLIHandler] quotes attribute values.
+type QuoteMode int
+
+// Quote modes.
+const (
+	// QuoteWhenNeeded quotes values that would be ambiguous
+	// otherwise. That is, empty values and values containing
+	// spaces, quotes, equal signs or non-printable characters.
+	QuoteWhenNeeded QuoteMode = iota
+
+	// QuoteAlways quotes every value.
+	QuoteAlways
+
+	// QuoteNever writes every value verbatim.
+	QuoteNever
+)
+
+// String returns a name for the quote mode.
+func (m QuoteMode) String() string {
+	switch m {
+	case QuoteWhenNeeded:
+		return "when-needed"
+	case QuoteAlways:
+		return "always"
+	case QuoteNever:
+		return "never"
+	default:
+		return fmt.Sprintf("QuoteMode(%d)", int(m))
+	}
+}
+
+// escape replaces the control characters in s with Go escape
+// sequences (e.g. "\n" or "\x1b").
+func escape(s string) string {
+	i := strings.IndexFunc(s, unicode.IsControl)
+	if i < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteString(s[:i])
+	for _, r := range s[i:] {
+		if !unicode.IsControl(r) {
+			b.WriteRune(r)
+			continue
+		}
+		q := strconv.QuoteRune(r)
+		b.WriteString(q[1 : len(q)-1])
+	}
+	return b.String()
+}
+
+// needsQuoting reports whether s must be quoted to be unambiguous.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if needsQuotingRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsQuotingBytes is like needsQuoting but for byte slices.
+func needsQuotingBytes(s []byte) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for len(s) > 0 {
+		r, size := utf8.DecodeRune(s)
+		if needsQuotingRune(r) {
+			return true
+		}
+		s = s[size:]
+	}
+	return false
+}
+
+// needsQuotingRune reports whether a string containing r must be
+// quoted.
+func needsQuotingRune(r rune) bool {
+	return r == ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r)
+}
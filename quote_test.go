@@ -0,0 +1,192 @@
+package clilog
+
+import (
+	"io"
+	"testing"
+)
+
+func TestCLIHandler_appendString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		mode QuoteMode
+		want string
+	}{
+		{
+			name: "when needed plain",
+			s:    "foo",
+			mode: QuoteWhenNeeded,
+			want: `foo`,
+		},
+		{
+			name: "when needed empty",
+			s:    "",
+			mode: QuoteWhenNeeded,
+			want: `""`,
+		},
+		{
+			name: "when needed space",
+			s:    "hello world",
+			mode: QuoteWhenNeeded,
+			want: `"hello world"`,
+		},
+		{
+			name: "when needed equal",
+			s:    "a=b",
+			mode: QuoteWhenNeeded,
+			want: `"a=b"`,
+		},
+		{
+			name: "when needed quote",
+			s:    `say "hi"`,
+			mode: QuoteWhenNeeded,
+			want: `"say \"hi\""`,
+		},
+		{
+			name: "when needed newline",
+			s:    "a\nb",
+			mode: QuoteWhenNeeded,
+			want: `"a\nb"`,
+		},
+		{
+			name: "when needed unicode",
+			s:    "héllo",
+			mode: QuoteWhenNeeded,
+			want: `héllo`,
+		},
+		{
+			name: "when needed invalid utf8",
+			s:    "\xff",
+			mode: QuoteWhenNeeded,
+			want: `"\xff"`,
+		},
+		{
+			name: "always",
+			s:    "foo",
+			mode: QuoteAlways,
+			want: `"foo"`,
+		},
+		{
+			name: "never",
+			s:    "hello world",
+			mode: QuoteNever,
+			want: `hello world`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCLIHandler(io.Discard, &HandlerOptions{Quote: tt.mode})
+			b := newBuffer()
+			defer b.free()
+			h.appendString(b, tt.s)
+			if got := string(*b); got != tt.want {
+				t.Errorf("unexpected result: got: %s, want: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsQuotingBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want bool
+	}{
+		{
+			name: "plain",
+			s:    "foo",
+			want: false,
+		},
+		{
+			name: "empty",
+			s:    "",
+			want: true,
+		},
+		{
+			name: "space",
+			s:    "hello world",
+			want: true,
+		},
+		{
+			name: "equal",
+			s:    "a=b",
+			want: true,
+		},
+		{
+			name: "quote",
+			s:    `say "hi"`,
+			want: true,
+		},
+		{
+			name: "newline",
+			s:    "a\nb",
+			want: true,
+		},
+		{
+			name: "unicode",
+			s:    "héllo",
+			want: false,
+		},
+		{
+			name: "invalid utf8",
+			s:    "\xff",
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsQuotingBytes([]byte(tt.s)); got != tt.want {
+				t.Errorf("unexpected result: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "plain",
+			s:    "foo bar",
+			want: `foo bar`,
+		},
+		{
+			name: "newline",
+			s:    "foo\nINFO forged",
+			want: `foo\nINFO forged`,
+		},
+		{
+			name: "carriage return",
+			s:    "foo\rbar",
+			want: `foo\rbar`,
+		},
+		{
+			name: "ansi",
+			s:    "\x1b[2Jfoo",
+			want: `\x1b[2Jfoo`,
+		},
+		{
+			name: "c1",
+			s:    "foo\u0085",
+			want: `foo\u0085`,
+		},
+		{
+			name: "unicode",
+			s:    "héllo",
+			want: `héllo`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escape(tt.s); got != tt.want {
+				t.Errorf("unexpected result: got: %s, want: %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultRateLimit is the number of records per second allowed by a
+// [RateLimitHandler] when [RateLimitOptions.Rate] is not positive.
+const DefaultRateLimit = 10
+
+// maxRateBuckets is the number of token buckets from which a
+// RateLimitHandler discards the full ones, so the memory used by
+// short-lived keys is bounded.
+const maxRateBuckets = 1024
+
+// RateLimitOptions are options for a [RateLimitHandler]. A zero
+// RateLimitOptions consists entirely of default values.
+type RateLimitOptions struct {
+	// Rate is the number of records per second allowed for every
+	// key. If Rate is not positive, the handler uses
+	// DefaultRateLimit.
+	Rate float64
+
+	// Burst is the maximum number of records allowed at once for
+	// every key. If Burst is not positive, the handler uses 1.
+	Burst int
+
+	// Key is the key of the attribute whose value identifies the
+	// records limited together. If Key is empty, or a record does
+	// not have a top-level attribute with that key, records are
+	// limited by message.
+	Key string
+
+	// Summarize causes the handler to add to the first record
+	// passed after dropping records with the same key the number
+	// of dropped records (see DroppedKey). Otherwise, excess
+	// records are dropped silently.
+	Summarize bool
+}
+
+// RateLimitHandler is a [slog.Handler] that limits the rate of the
+// records passed to another handler using a token bucket per message
+// or per value of a key attribute. Excess records are dropped, so a
+// warning logged in a hot path does not flood the output.
+//
+// Tokens are refilled using the time of the records. Handlers derived
+// from a RateLimitHandler using WithAttrs or WithGroup share its
+// buckets.
+type RateLimitHandler struct {
+	h    slog.Handler
+	opts RateLimitOptions
+	l    *rateLimiter
+}
+
+// rateLimiter holds the token buckets shared by a RateLimitHandler and
+// the handlers derived from it.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket is the token bucket of a key.
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time // time of the last refill
+	dropped int       // records dropped since the last one passed
+}
+
+// NewRateLimitHandler returns a new [RateLimitHandler] that passes
+// records to h. If opts is nil, the default options are used.
+func NewRateLimitHandler(h slog.Handler, opts *RateLimitOptions) *RateLimitHandler {
+	if opts == nil {
+		opts = &RateLimitOptions{}
+	}
+	rh := &RateLimitHandler{h: h, opts: *opts}
+	if rh.opts.Rate <= 0 {
+		rh.opts.Rate = DefaultRateLimit
+	}
+	if rh.opts.Burst <= 0 {
+		rh.opts.Burst = 1
+	}
+	rh.l = &rateLimiter{buckets: make(map[string]*tokenBucket)}
+	return rh
+}
+
+// Enabled reports whether the underlying handler handles records at
+// the given level.
+func (h *RateLimitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the underlying handler if its bucket
+// has tokens left. Otherwise, the record is dropped.
+func (h *RateLimitHandler) Handle(ctx context.Context, r slog.Record) error {
+	dropped, ok := h.l.allow(h.key(r), r.Time, h.opts)
+	if !ok {
+		return nil
+	}
+	if dropped > 0 && h.opts.Summarize {
+		r = r.Clone()
+		r.AddAttrs(slog.Int(DroppedKey, dropped))
+	}
+	return h.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both
+// the receiver's attributes and the arguments.
+func (h *RateLimitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RateLimitHandler{h: h.h.WithAttrs(attrs), opts: h.opts, l: h.l}
+}
+
+// WithGroup returns a new Handler with the given group appended to
+// the receiver's existing groups.
+func (h *RateLimitHandler) WithGroup(name string) slog.Handler {
+	return &RateLimitHandler{h: h.h.WithGroup(name), opts: h.opts, l: h.l}
+}
+
+// key returns the key of the bucket of r. Keys derived from messages
+// and from attributes are prefixed differently, so they never
+// collide.
+func (h *RateLimitHandler) key(r slog.Record) string {
+	key := "msg:" + r.Message
+	if h.opts.Key == "" {
+		return key
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key != h.opts.Key {
+			return true
+		}
+		key = "attr:" + a.Value.Resolve().String()
+		return false
+	})
+	return key
+}
+
+// allow reports whether a record with the provided key and time can be
+// passed and, if so, the number of records with the same key dropped
+// before it.
+func (l *rateLimiter) allow(key string, t time.Time, opts RateLimitOptions) (dropped int, ok bool) {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	burst := float64(opts.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.buckets[key]
+	if b == nil {
+		if len(l.buckets) >= maxRateBuckets {
+			l.prune(t, opts)
+		}
+		b = &tokenBucket{tokens: burst, last: t}
+		l.buckets[key] = b
+	}
+	if elapsed := t.Sub(b.last); elapsed > 0 {
+		b.tokens = min(burst, b.tokens+elapsed.Seconds()*opts.Rate)
+		b.last = t
+	}
+	if b.tokens < 1 {
+		b.dropped++
+		return 0, false
+	}
+	b.tokens--
+	dropped, b.dropped = b.dropped, 0
+	return dropped, true
+}
+
+// prune discards the buckets that would be full at time t and have
+// not dropped records, as they behave like new buckets.
+func (l *rateLimiter) prune(t time.Time, opts RateLimitOptions) {
+	for k, b := range l.buckets {
+		if b.dropped == 0 && b.tokens+t.Sub(b.last).Seconds()*opts.Rate >= float64(opts.Burst) {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *RateLimitHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
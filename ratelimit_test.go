@@ -0,0 +1,72 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *RateLimitOptions
+		want string
+	}{
+		{
+			name: "message",
+			opts: &RateLimitOptions{Rate: 2, Burst: 2},
+			want: "WARN slow host=a i=0\n" +
+				"WARN slow host=b i=1\n" +
+				"INFO other\n" +
+				"WARN slow host=a i=6\n",
+		},
+		{
+			name: "summarize",
+			opts: &RateLimitOptions{Rate: 2, Burst: 2, Summarize: true},
+			want: "WARN slow host=a i=0\n" +
+				"WARN slow host=b i=1\n" +
+				"INFO other\n" +
+				"WARN slow host=a i=6 dropped=4\n",
+		},
+		{
+			name: "key",
+			opts: &RateLimitOptions{Rate: 2, Key: "host"},
+			want: "WARN slow host=a i=0\n" +
+				"WARN slow host=b i=1\n" +
+				"INFO other\n" +
+				"WARN slow host=a i=6\n" +
+				"WARN slow host=b i=7\n",
+		},
+		{
+			name: "default",
+			want: "WARN slow host=a i=0\n" +
+				"INFO other\n" +
+				"WARN slow host=a i=6\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			h := NewRateLimitHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), tt.opts)
+			logger := slog.New(setTimeHandler{testTime, h})
+			for i := 0; i < 6; i++ {
+				logger.Warn("slow", "host", string(rune('a'+i%2)), "i", i)
+			}
+			logger.Info("other")
+
+			// Half a second later, the buckets have been
+			// partially refilled.
+			logger = slog.New(setTimeHandler{testTime.Add(500 * time.Millisecond), h})
+			for i := 6; i < 9; i++ {
+				logger.Warn("slow", "host", string(rune('a'+i%2)), "i", i)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
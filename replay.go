@@ -0,0 +1,163 @@
+package clilog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// DefaultReplaySize is the number of records buffered by a
+// [ReplayHandler] when [ReplayOptions.Size] is not positive.
+const DefaultReplaySize = 100
+
+// ReplayOptions are options for a [ReplayHandler]. A zero
+// ReplayOptions consists entirely of default values.
+type ReplayOptions struct {
+	// Size is the maximum number of buffered records. When the
+	// buffer is full, the oldest record is discarded. If Size is
+	// not positive, the handler uses DefaultReplaySize.
+	Size int
+
+	// Level is the minimum level of the buffered records. If Level
+	// is nil, the handler assumes LevelDebug.
+	Level slog.Leveler
+
+	// Trigger is the minimum level of the records that cause the
+	// buffered records to be replayed. If Trigger is nil, the
+	// handler assumes LevelError.
+	Trigger slog.Leveler
+}
+
+// ReplayHandler is a [slog.Handler] that keeps the records discarded
+// by another handler in a ring buffer and, when a record at or above
+// the trigger level arrives, replays them before it. This provides
+// verbose logs only when something goes wrong.
+//
+// Handlers derived from a ReplayHandler using WithAttrs or WithGroup
+// share its buffer.
+type ReplayHandler struct {
+	h    slog.Handler
+	opts ReplayOptions
+	ring *replayRing
+}
+
+// replayRing is the ring buffer shared by a ReplayHandler and the
+// handlers derived from it.
+type replayRing struct {
+	mu      sync.Mutex
+	entries []replayEntry
+	start   int // index of the oldest entry
+	n       int // number of entries
+}
+
+// replayEntry is a buffered record.
+type replayEntry struct {
+	h   slog.Handler
+	ctx context.Context
+	r   slog.Record
+}
+
+// NewReplayHandler returns a new [ReplayHandler] that passes records
+// to h. If opts is nil, the default options are used.
+func NewReplayHandler(h slog.Handler, opts *ReplayOptions) *ReplayHandler {
+	if opts == nil {
+		opts = &ReplayOptions{}
+	}
+	rh := &ReplayHandler{h: h, opts: *opts}
+	if rh.opts.Size <= 0 {
+		rh.opts.Size = DefaultReplaySize
+	}
+	if rh.opts.Level == nil {
+		rh.opts.Level = slog.LevelDebug
+	}
+	if rh.opts.Trigger == nil {
+		rh.opts.Trigger = slog.LevelError
+	}
+	rh.ring = &replayRing{entries: make([]replayEntry, rh.opts.Size)}
+	return rh
+}
+
+// Enabled reports whether the handler handles records at the given
+// level. Records are handled if they are buffered or if the
+// underlying handler handles them.
+func (h *ReplayHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level() || h.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the underlying handler if it is enabled
+// for its level. Otherwise, it buffers the record. If the level of
+// the record is at or above the trigger level, the buffered records
+// are replayed first.
+func (h *ReplayHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.opts.Trigger.Level() {
+		h.ring.mu.Lock()
+		defer h.ring.mu.Unlock()
+
+		var errs []error
+		for _, e := range h.ring.drain() {
+			if err := e.h.Handle(e.ctx, e.r); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if h.h.Enabled(ctx, r.Level) {
+			if err := h.h.Handle(ctx, r); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	if h.h.Enabled(ctx, r.Level) {
+		return h.h.Handle(ctx, r)
+	}
+
+	if r.Level >= h.opts.Level.Level() {
+		h.ring.mu.Lock()
+		h.ring.push(replayEntry{h: h.h, ctx: context.WithoutCancel(ctx), r: r.Clone()})
+		h.ring.mu.Unlock()
+	}
+	return nil
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both
+// the receiver's attributes and the arguments.
+func (h *ReplayHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ReplayHandler{h: h.h.WithAttrs(attrs), opts: h.opts, ring: h.ring}
+}
+
+// WithGroup returns a new Handler with the given group appended to
+// the receiver's existing groups.
+func (h *ReplayHandler) WithGroup(name string) slog.Handler {
+	return &ReplayHandler{h: h.h.WithGroup(name), opts: h.opts, ring: h.ring}
+}
+
+// push adds an entry to the ring, discarding the oldest one if it is
+// full.
+func (r *replayRing) push(e replayEntry) {
+	i := (r.start + r.n) % len(r.entries)
+	r.entries[i] = e
+	if r.n < len(r.entries) {
+		r.n++
+	} else {
+		r.start = (r.start + 1) % len(r.entries)
+	}
+}
+
+// drain removes all the entries from the ring and returns them from
+// oldest to newest.
+func (r *replayRing) drain() []replayEntry {
+	entries := make([]replayEntry, r.n)
+	for i := range entries {
+		j := (r.start + i) % len(r.entries)
+		entries[i] = r.entries[j]
+		r.entries[j] = replayEntry{}
+	}
+	r.start, r.n = 0, 0
+	return entries
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *ReplayHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
@@ -0,0 +1,52 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestReplayHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewReplayHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), &ReplayOptions{Size: 2})
+	logger := slog.New(h)
+
+	logger.Debug("debug 1")
+	logger.Info("info")
+	logger.WithGroup("g").Debug("debug 2", "a", 1)
+	logger.With("b", 2).Debug("debug 3")
+
+	if got, want := buf.String(), "INFO info\n"; got != want {
+		t.Fatalf("unexpected output before error:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	logger.Error("error")
+	logger.Debug("debug 4")
+
+	want := "INFO info\n" +
+		"DEBUG debug 2 g.a=1\n" +
+		"DEBUG debug 3 b=2\n" +
+		"ERROR error\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestReplayHandler_Level(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewReplayHandler(
+		NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, Level: slog.LevelWarn}),
+		&ReplayOptions{Level: slog.LevelInfo, Trigger: slog.LevelWarn},
+	)
+	logger := slog.New(h)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+
+	if got, want := buf.String(), "INFO info\nWARN warn\n"; got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
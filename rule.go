@@ -0,0 +1,162 @@
+package clilog
+
+import (
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// LevelRule sets the minimum level of the records logged under a group
+// or from a package. It allows to enable verbose logging for a
+// specific subsystem without enabling it for the whole program.
+type LevelRule struct {
+	// Group is a dot-separated list of groups (e.g. "db" or
+	// "db.conn"). The rule applies to the handlers whose groups,
+	// added with WithGroup, start with Group. A trailing ".*" is
+	// ignored, so "db" and "db.*" are equivalent. If Group is
+	// empty, the rule applies to all the handlers.
+	Group string
+
+	// Package is an import path (e.g. "github.com/user/tool/db").
+	// The rule applies to the records logged from functions of the
+	// package or any of its subpackages. If Package is empty, the
+	// rule applies to all the packages.
+	Package string
+
+	// Level is the minimum level of the records matching the rule.
+	Level slog.Leveler
+}
+
+// matchGroup reports whether the rule applies to a handler with the
+// provided groups.
+func (rule LevelRule) matchGroup(groups []string) bool {
+	prefix := strings.TrimSuffix(rule.Group, ".*")
+	if prefix == "" {
+		return true
+	}
+	path := strings.Join(groups, ".")
+	return path == prefix || strings.HasPrefix(path, prefix+".")
+}
+
+// matchPackage reports whether the rule applies to a record logged from
+// the function with the provided fully qualified name.
+func (rule LevelRule) matchPackage(fn string) bool {
+	if rule.Package == "" {
+		return true
+	}
+	pkg := funcPackage(fn)
+	return pkg == rule.Package || strings.HasPrefix(pkg, rule.Package+"/")
+}
+
+// specificity returns the specificity of the rule. More specific rules
+// take precedence.
+func (rule LevelRule) specificity() int {
+	return len(strings.TrimSuffix(rule.Group, ".*")) + len(rule.Package)
+}
+
+// groupRules returns the rules that apply to a handler with the
+// provided groups, sorted by decreasing specificity. It also reports
+// whether any of the returned rules depends on the package.
+func groupRules(rules []LevelRule, groups []string) ([]LevelRule, bool) {
+	var (
+		matched []LevelRule
+		hasPkg  bool
+	)
+	for _, rule := range rules {
+		if !rule.matchGroup(groups) {
+			continue
+		}
+		matched = append(matched, rule)
+		hasPkg = hasPkg || rule.Package != ""
+	}
+	slices.SortStableFunc(matched, func(a, b LevelRule) int {
+		return b.specificity() - a.specificity()
+	})
+	return matched, hasPkg
+}
+
+// minLevel returns the minimum level of the records logged from pc.
+// If pc is zero, package rules are ignored.
+func (h *CLIHandler) minLevel(pc uintptr) slog.Level {
+	var fn string
+	if pc != 0 && h.pkgRules {
+		fn = funcNames.name(pc)
+	}
+	for _, rule := range h.rules {
+		if rule.Package != "" && (fn == "" || !rule.matchPackage(fn)) {
+			continue
+		}
+		return rule.Level.Level()
+	}
+	return h.defaultLevel()
+}
+
+// lowestLevel returns the lowest minimum level among the records that
+// can be handled by h, regardless of the package they are logged from.
+func (h *CLIHandler) lowestLevel() slog.Level {
+	level := h.minLevel(0)
+	if !h.pkgRules {
+		return level
+	}
+	for _, rule := range h.rules {
+		if rule.Package != "" {
+			level = min(level, rule.Level.Level())
+		}
+	}
+	return level
+}
+
+// defaultLevel returns the minimum level of the records that do not
+// match any rule.
+func (h *CLIHandler) defaultLevel() slog.Level {
+	if level := h.state.level.Load(); level != nil {
+		return (*level).Level()
+	}
+	if h.opts.Level == nil {
+		return slog.LevelInfo
+	}
+	return h.opts.Level.Level()
+}
+
+// funcNames caches the names of the functions containing the program
+// counters of the handled records, so looking them up does not
+// allocate.
+var funcNames = funcCache{m: make(map[uintptr]string)}
+
+// funcCache maps program counters to fully qualified function names.
+type funcCache struct {
+	mu sync.RWMutex
+	m  map[uintptr]string
+}
+
+// name returns the fully qualified name of the function containing
+// pc.
+func (c *funcCache) name(pc uintptr) string {
+	c.mu.RLock()
+	fn, ok := c.m[pc]
+	c.mu.RUnlock()
+	if ok {
+		return fn
+	}
+
+	if f := runtime.FuncForPC(pc); f != nil {
+		fn = f.Name()
+	}
+
+	c.mu.Lock()
+	c.m[pc] = fn
+	c.mu.Unlock()
+	return fn
+}
+
+// funcPackage returns the import path of the package of the function
+// with the provided fully qualified name.
+func funcPackage(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if i := strings.Index(fn[slash+1:], "."); i >= 0 {
+		return fn[:slash+1+i]
+	}
+	return fn
+}
@@ -0,0 +1,169 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCLIHandler_LevelRules(t *testing.T) {
+	rules := []LevelRule{
+		{Group: "db.*", Level: slog.LevelDebug},
+		{Group: "db.conn", Level: slog.LevelError},
+		{Group: "http", Package: "github.com/jroimartin/clilog", Level: LevelTrace},
+		{Group: "http", Package: "github.com/jroimartin/clilog/other", Level: slog.LevelError},
+		{Package: "example.com/other", Level: slog.LevelError},
+	}
+
+	tests := []struct {
+		name  string
+		group string
+		level slog.Level
+		want  bool
+	}{
+		{
+			name:  "no group info",
+			group: "",
+			level: slog.LevelInfo,
+			want:  true,
+		},
+		{
+			name:  "no group debug",
+			group: "",
+			level: slog.LevelDebug,
+			want:  false,
+		},
+		{
+			name:  "db debug",
+			group: "db",
+			level: slog.LevelDebug,
+			want:  true,
+		},
+		{
+			name:  "db subgroup debug",
+			group: "db.tx",
+			level: slog.LevelDebug,
+			want:  true,
+		},
+		{
+			name:  "db prefix",
+			group: "dbx",
+			level: slog.LevelDebug,
+			want:  false,
+		},
+		{
+			name:  "db.conn warn",
+			group: "db.conn",
+			level: slog.LevelWarn,
+			want:  false,
+		},
+		{
+			name:  "db.conn error",
+			group: "db.conn",
+			level: slog.LevelError,
+			want:  true,
+		},
+		{
+			name:  "http package trace",
+			group: "http",
+			level: LevelTrace,
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, LevelRules: rules})
+			logger := slog.New(h)
+			if tt.group != "" {
+				for _, g := range strings.Split(tt.group, ".") {
+					logger = logger.WithGroup(g)
+				}
+			}
+
+			logger.Log(context.Background(), tt.level, "message")
+
+			if got := buf.Len() > 0; got != tt.want {
+				t.Errorf("unexpected result: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_LevelRules_Enabled(t *testing.T) {
+	h := NewCLIHandler(&bytes.Buffer{}, &HandlerOptions{
+		LevelRules: []LevelRule{
+			{Package: "example.com/other", Level: LevelTrace},
+		},
+	}).WithGroup("g")
+
+	// The package is unknown at this point, so Enabled must not
+	// discard records that could match the package rule.
+	if !h.Enabled(context.Background(), LevelTrace) {
+		t.Errorf("Enabled returned false")
+	}
+
+	// Handle must discard the record, because it is not logged from
+	// the package.
+	var buf bytes.Buffer
+	h = NewCLIHandler(&buf, &HandlerOptions{
+		LevelRules: []LevelRule{
+			{Package: "example.com/other", Level: LevelTrace},
+		},
+	})
+	slog.New(h).Log(context.Background(), LevelTrace, "message")
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want string
+	}{
+		{"main.main", "main"},
+		{"github.com/user/tool.run", "github.com/user/tool"},
+		{"github.com/user/tool/db.(*Conn).Query", "github.com/user/tool/db"},
+		{"github.com/user/tool.v2/db.run.func1", "github.com/user/tool.v2/db"},
+		{"nodot", "nodot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			if got := funcPackage(tt.fn); got != tt.want {
+				t.Errorf("unexpected package: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_LevelRules_allocs(t *testing.T) {
+	h := NewCLIHandler(&bytes.Buffer{}, &HandlerOptions{
+		LevelRules: []LevelRule{
+			{Group: "db", Level: slog.LevelDebug},
+			{Package: "example.com/other", Level: LevelTrace},
+		},
+	}).WithGroup("db").(*CLIHandler)
+
+	ctx := context.Background()
+	pc := thisPC()
+	allocs := testing.AllocsPerRun(100, func() {
+		h.Enabled(ctx, slog.LevelDebug)
+		h.minLevel(pc)
+	})
+	if allocs != 0 {
+		t.Errorf("unexpected allocations: %v", allocs)
+	}
+}
+
+func thisPC() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	return pcs[0]
+}
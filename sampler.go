@@ -0,0 +1,166 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Defaults of [SamplerOptions].
+const (
+	DefaultSampleTick  = time.Second
+	DefaultSampleFirst = 10
+)
+
+// DroppedKey is the key of the attribute added by [SamplerHandler]
+// to the records that follow dropped ones. Its value is the number of
+// records with the same level and message dropped since the last
+// record passed.
+const DroppedKey = "dropped"
+
+// SamplerOptions are options for a [SamplerHandler]. A zero
+// SamplerOptions consists entirely of default values.
+type SamplerOptions struct {
+	// Tick is the sampling interval. If Tick is not positive, the
+	// handler uses DefaultSampleTick.
+	Tick time.Duration
+
+	// First is the number of records with the same level and
+	// message passed every interval before sampling starts. If
+	// First is not positive, the handler uses DefaultSampleFirst.
+	First int
+
+	// Thereafter causes the handler to pass one of every
+	// Thereafter records with the same level and message once
+	// First records have been passed in the current interval. If
+	// Thereafter is not positive, those records are dropped.
+	Thereafter int
+}
+
+// SamplerHandler is a [slog.Handler] that limits the number of records
+// with the same level and message passed to another handler per
+// interval. Every interval, the first records are passed and then one
+// of every Thereafter records, so loops logging in every iteration do
+// not flood the output. The first record passed after dropping records
+// includes the number of dropped records (see DroppedKey).
+//
+// Intervals are measured using the time of the records. Handlers
+// derived from a SamplerHandler using WithAttrs or WithGroup share its
+// counters.
+type SamplerHandler struct {
+	h    slog.Handler
+	opts SamplerOptions
+	s    *sampler
+}
+
+// sampler holds the counters shared by a SamplerHandler and the
+// handlers derived from it.
+type sampler struct {
+	mu     sync.Mutex
+	start  time.Time // start of the current interval
+	counts map[sampleKey]*sampleCount
+}
+
+// sampleKey identifies the records sampled together.
+type sampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+// sampleCount counts the records with the same key.
+type sampleCount struct {
+	n       int // records seen in the current interval
+	dropped int // records dropped since the last one passed
+}
+
+// NewSamplerHandler returns a new [SamplerHandler] that passes records
+// to h. If opts is nil, the default options are used.
+func NewSamplerHandler(h slog.Handler, opts *SamplerOptions) *SamplerHandler {
+	if opts == nil {
+		opts = &SamplerOptions{}
+	}
+	sh := &SamplerHandler{h: h, opts: *opts}
+	if sh.opts.Tick <= 0 {
+		sh.opts.Tick = DefaultSampleTick
+	}
+	if sh.opts.First <= 0 {
+		sh.opts.First = DefaultSampleFirst
+	}
+	sh.s = &sampler{counts: make(map[sampleKey]*sampleCount)}
+	return sh
+}
+
+// Enabled reports whether the underlying handler handles records at
+// the given level.
+func (h *SamplerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the underlying handler unless it is
+// dropped by the sampler.
+func (h *SamplerHandler) Handle(ctx context.Context, r slog.Record) error {
+	dropped, ok := h.s.sample(r, h.opts)
+	if !ok {
+		return nil
+	}
+	if dropped > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int(DroppedKey, dropped))
+	}
+	return h.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both
+// the receiver's attributes and the arguments.
+func (h *SamplerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplerHandler{h: h.h.WithAttrs(attrs), opts: h.opts, s: h.s}
+}
+
+// WithGroup returns a new Handler with the given group appended to
+// the receiver's existing groups.
+func (h *SamplerHandler) WithGroup(name string) slog.Handler {
+	return &SamplerHandler{h: h.h.WithGroup(name), opts: h.opts, s: h.s}
+}
+
+// sample reports whether r must be passed and, if so, the number of
+// records with the same key dropped before it.
+func (s *sampler) sample(r slog.Record, opts SamplerOptions) (dropped int, ok bool) {
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.Sub(s.start) >= opts.Tick || t.Before(s.start) {
+		s.start = t
+		for k, c := range s.counts {
+			if c.dropped == 0 {
+				delete(s.counts, k)
+				continue
+			}
+			c.n = 0
+		}
+	}
+
+	k := sampleKey{level: r.Level, msg: r.Message}
+	c := s.counts[k]
+	if c == nil {
+		c = &sampleCount{}
+		s.counts[k] = c
+	}
+	c.n++
+	if c.n > opts.First && (opts.Thereafter <= 0 || (c.n-opts.First)%opts.Thereafter != 0) {
+		c.dropped++
+		return 0, false
+	}
+	dropped, c.dropped = c.dropped, 0
+	return dropped, true
+}
+
+// wrapped returns the handler wrapped by h.
+func (h *SamplerHandler) wrapped() []slog.Handler {
+	return []slog.Handler{h.h}
+}
@@ -0,0 +1,84 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSamplerHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *SamplerOptions
+		want string
+	}{
+		{
+			name: "first",
+			opts: &SamplerOptions{First: 2},
+			want: "INFO item i=0\n" +
+				"INFO item i=1\n" +
+				"WARN other\n" +
+				"INFO item i=0 dropped=4\n" +
+				"INFO item i=1\n",
+		},
+		{
+			name: "thereafter",
+			opts: &SamplerOptions{First: 1, Thereafter: 2},
+			want: "INFO item i=0\n" +
+				"INFO item i=2 dropped=1\n" +
+				"INFO item i=4 dropped=1\n" +
+				"WARN other\n" +
+				"INFO item i=0 dropped=1\n" +
+				"INFO item i=2 dropped=1\n",
+		},
+		{
+			name: "default",
+			want: "INFO item i=0\n" +
+				"INFO item i=1\n" +
+				"INFO item i=2\n" +
+				"INFO item i=3\n" +
+				"INFO item i=4\n" +
+				"INFO item i=5\n" +
+				"WARN other\n" +
+				"INFO item i=0\n" +
+				"INFO item i=1\n" +
+				"INFO item i=2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			h := NewSamplerHandler(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}), tt.opts)
+			now := testTime
+			logger := slog.New(setTimeHandler{now, h})
+			for i := 0; i < 6; i++ {
+				logger.Info("item", "i", i)
+			}
+			logger.Warn("other")
+
+			// The next interval starts one second later.
+			logger = slog.New(setTimeHandler{now.Add(time.Second), h.WithAttrs(nil)})
+			for i := 0; i < 3; i++ {
+				logger.Info("item", "i", i)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamplerHandler_Enabled(t *testing.T) {
+	h := NewSamplerHandler(NewCLIHandler(&bytes.Buffer{}, &HandlerOptions{Level: slog.LevelWarn}), nil)
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("info records are enabled")
+	}
+	if !h.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("warn records are disabled")
+	}
+}
@@ -0,0 +1,35 @@
+package clilog
+
+import "log/slog"
+
+// secretMask is the representation of a [Secret].
+const secretMask = "***"
+
+// Secret is a string that is masked when logged, so credentials can
+// be passed through log attributes safely. A Secret is rendered as
+// "***" by any [slog.Handler]. A [CLIHandler] can be configured to
+// reveal its last characters using [HandlerOptions.RevealSecrets].
+type Secret string
+
+// LogValue implements [slog.LogValuer]. It returns the masked
+// secret.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(secretMask)
+}
+
+// String returns the masked secret. It prevents the secret from
+// being disclosed when formatted with the fmt package.
+func (s Secret) String() string {
+	return secretMask
+}
+
+// reveal returns the masked secret followed by its last n characters.
+// If the secret is not longer than n characters, it is completely
+// masked.
+func (s Secret) reveal(n int) string {
+	r := []rune(string(s))
+	if n <= 0 || len(r) <= n {
+		return secretMask
+	}
+	return secretMask + string(r[len(r)-n:])
+}
@@ -0,0 +1,64 @@
+package clilog
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("message", "token", Secret("abcdef"))
+	if got, want := buf.String(), "token=***"; !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("secret not masked: %v", got)
+	}
+
+	if got := fmt.Sprint(Secret("abcdef")); got != "***" {
+		t.Errorf("secret not masked by fmt: %v", got)
+	}
+}
+
+func TestSecret_reveal(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Secret
+		n    int
+		want string
+	}{
+		{
+			name: "zero",
+			s:    "abcdef",
+			n:    0,
+			want: "***",
+		},
+		{
+			name: "last",
+			s:    "abcdef",
+			n:    2,
+			want: "***ef",
+		},
+		{
+			name: "short",
+			s:    "abc",
+			n:    3,
+			want: "***",
+		},
+		{
+			name: "unicode",
+			s:    "pässwörd",
+			n:    3,
+			want: "***örd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.reveal(tt.n); got != tt.want {
+				t.Errorf("unexpected result: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package clilog
+
+import "log/slog"
+
+// sectionIndent is the indentation of the records per level of
+// nested sections.
+const sectionIndent = "  "
+
+// Section logs title at LevelInfo and starts a section: the records
+// handled until the matching call to EndSection are indented under
+// it. Sections can be nested. The indentation affects the handler of
+// logger and every handler sharing its output.
+//
+// With FormatGitHub, FormatGitLab and FormatAzure, sections are
+// rendered as collapsible groups (see CLIHandler.StartGroup) instead.
+// With other formats than FormatText, the records are not indented.
+// If the handler of logger is not a [CLIHandler], Section only logs
+// the title.
+func Section(logger *slog.Logger, title string) {
+	h, ok := logger.Handler().(*CLIHandler)
+	if ok && h.isCI() {
+		h.StartGroup(title) //nolint:errcheck
+		return
+	}
+	logCaller(logger, 0, slog.LevelInfo, title, nil)
+	if ok {
+		h.state.sections.Add(1)
+	}
+}
+
+// EndSection ends the section started by the last call to Section.
+func EndSection(logger *slog.Logger) {
+	h, ok := logger.Handler().(*CLIHandler)
+	if !ok {
+		return
+	}
+	if h.isCI() {
+		h.EndGroup() //nolint:errcheck
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.state.sections.Load() > 0 {
+		h.state.sections.Add(-1)
+	}
+}
+
+// isCI reports whether the output format of h renders collapsible
+// groups.
+func (h *CLIHandler) isCI() bool {
+	switch h.opts.Format {
+	case FormatGitHub, FormatGitLab, FormatAzure:
+		return true
+	default:
+		return false
+	}
+}
+
+// appendSectionIndent appends to b the indentation of the records
+// within sections.
+func (h *CLIHandler) appendSectionIndent(b *buffer) {
+	if h.opts.Format != FormatText {
+		return
+	}
+	for i := h.state.sections.Load(); i > 0; i-- {
+		b.WriteString(sectionIndent)
+	}
+}
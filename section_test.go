@@ -0,0 +1,79 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestSection(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}))
+
+	Section(logger, "Running tests")
+	logger.Info("unit")
+	Section(logger.With("pkg", "db"), "Integration")
+	logger.Warn("slow")
+	EndSection(logger)
+	logger.Info("e2e")
+	EndSection(logger)
+	EndSection(logger)
+	logger.Info("done")
+
+	want := "INFO Running tests\n" +
+		"  INFO unit\n" +
+		"  INFO Integration pkg=db\n" +
+		"    WARN slow\n" +
+		"  INFO e2e\n" +
+		"INFO done\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestSection_logfmt(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, Format: FormatLogfmt}))
+
+	Section(logger, "Running tests")
+	logger.Info("unit")
+	EndSection(logger)
+
+	want := "level=INFO msg=\"Running tests\"\nlevel=INFO msg=unit\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestSection_ci(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, Format: FormatGitHub}))
+
+	Section(logger, "Running tests")
+	logger.Info("unit")
+	EndSection(logger)
+
+	want := "::group::Running tests\nINFO unit\n::endgroup::\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestSection_otherHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	Section(logger, "Running tests")
+	EndSection(logger)
+
+	if got, want := buf.String(), "level=INFO msg=\"Running tests\"\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package clilog
+
+import (
+	"io"
+	"log/slog"
+	"sync/atomic"
+)
+
+// handlerState holds the settings of a handler that can be changed
+// at runtime. It is shared by the handler and the handlers derived
+// from it. The outputs and the level are loaded atomically, so
+// handling a record does not require locking. Updates are serialized
+// by the mutex of the handler.
+type handlerState struct {
+	out       atomic.Pointer[output]                 // output of records
+	errOut    atomic.Pointer[output]                 // output of records above SplitLevel, if any
+	levelOuts atomic.Pointer[map[slog.Level]*output] // outputs of the writers returned by WriterFor
+	level     atomic.Pointer[slog.Leveler]           // level set by SetLevel, if any
+	color     ColorMode                              // color mode of the outputs
+	errors    atomic.Uint64                          // number of write errors
+	counts    [7]atomic.Uint64                       // number of records per named level
+	last      atomic.Int64                           // time of the last record in Unix nanoseconds, used by Delta
+
+	sections atomic.Int32  // depth of the sections started by Section
+	progress progressState // state of the progress lines, protected by mu
+}
+
+// Errors returns the number of errors returned by the writers of the
+// handler and the handlers derived from it.
+func (h *CLIHandler) Errors() uint64 {
+	return h.state.errors.Load()
+}
+
+// Level returns the minimum level of the records that do not match
+// any level rule.
+func (h *CLIHandler) Level() slog.Level {
+	return h.defaultLevel()
+}
+
+// SetLevel sets the minimum level of the records that do not match
+// any level rule, replacing [HandlerOptions.Level]. It is safe to call
+// SetLevel concurrently with logging. The change affects the handler
+// and every handler derived from it with WithAttrs or WithGroup.
+func (h *CLIHandler) SetLevel(level slog.Leveler) {
+	h.state.level.Store(&level)
+}
+
+// SetColor sets the color mode of the handler, replacing
+// [HandlerOptions.Color]. The handlers using the logfmt and journald
+// formats never colorize their output. It is safe to call SetColor
+// concurrently with logging. The change affects the handler and every
+// handler derived from it with WithAttrs or WithGroup.
+func (h *CLIHandler) SetColor(mode ColorMode) {
+	if h.opts.Format == FormatLogfmt || h.opts.Format == FormatJournald {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.state.color = mode
+	h.state.out.Store(h.state.out.Load().withColor(mode))
+	if errOut := h.state.errOut.Load(); errOut != nil {
+		h.state.errOut.Store(errOut.withColor(mode))
+	}
+	h.updateLevelOutputs(func(out *output) *output {
+		return out.withColor(mode)
+	})
+}
+
+// withColor returns a copy of o with colors enabled according to
+// mode.
+func (o *output) withColor(mode ColorMode) *output {
+	o2 := newOutput(o.w, mode)
+	o2.fallback = o.fallback
+	return o2
+}
+
+// failover replaces out, which failed, with an output writing to
+// Fallback. It returns the new output, or nil if out cannot be
+// replaced because there is no Fallback, out is already a fallback or
+// out has been replaced concurrently. h.mu must be held.
+func (h *CLIHandler) failover(out *output) *output {
+	if h.opts.Fallback == nil || out.fallback {
+		return nil
+	}
+	fallback := newOutput(h.opts.Fallback, h.state.color)
+	fallback.fallback = true
+	if !h.replaceOutput(out, fallback) {
+		return nil
+	}
+	return fallback
+}
+
+// stopOutput replaces out, which failed with err, with an output that
+// discards the records if StopOnBrokenPipe is set and err is caused
+// by a broken pipe. It reports whether out has been replaced. h.mu
+// must be held.
+func (h *CLIHandler) stopOutput(out *output, err error) bool {
+	if !h.opts.StopOnBrokenPipe || !isBrokenPipe(err) {
+		return false
+	}
+	return h.replaceOutput(out, &output{w: io.Discard, fallback: true})
+}
+
+// replaceOutput replaces old, which is either the output, the error
+// output or an output returned by WriterFor of h, with out. It
+// returns false if old has been replaced concurrently. h.mu must be
+// held.
+func (h *CLIHandler) replaceOutput(old, out *output) bool {
+	switch old {
+	case h.state.out.Load():
+		h.state.out.Store(out)
+	case h.state.errOut.Load():
+		h.state.errOut.Store(out)
+	default:
+		replaced := false
+		h.updateLevelOutputs(func(o *output) *output {
+			if o != old {
+				return o
+			}
+			replaced = true
+			return out
+		})
+		return replaced
+	}
+	return true
+}
+
+// SetOutput sets the writer of the records. Handlers created by
+// [NewCLIHandlerSplit] keep writing the records above SplitLevel to
+// stderr. Colors are enabled for w according to the current color
+// mode, but the output format is not resolved again. It is safe to
+// call SetOutput concurrently with logging, although the records
+// being handled at the same time may be written to the previous
+// writer. The change affects the handler and every handler derived
+// from it with WithAttrs or WithGroup.
+func (h *CLIHandler) SetOutput(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.state.out.Store(newOutput(w, h.state.color))
+}
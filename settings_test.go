@@ -0,0 +1,195 @@
+package clilog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func TestCLIHandler_SetLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true})
+	logger := slog.New(h).With("a", 1)
+
+	logger.Debug("hidden")
+	h.SetLevel(slog.LevelDebug)
+	logger.Debug("shown")
+	if got := h.Level(); got != slog.LevelDebug {
+		t.Errorf("unexpected level: got: %v, want: %v", got, slog.LevelDebug)
+	}
+
+	want := "DEBUG shown a=1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestCLIHandler_SetColor(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, Theme: &Theme{Info: "<info>"}})
+	logger := slog.New(h.WithGroup("g"))
+
+	logger.Info("plain")
+	h.SetColor(ColorAlways)
+	logger.Info("colored")
+	h.SetColor(ColorNever)
+	logger.Info("plain")
+
+	want := "INFO plain\n<info>INFO\x1b[0m colored\nINFO plain\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestCLIHandler_SetOutput(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+
+	h := NewCLIHandler(&buf1, &HandlerOptions{OmitTime: true})
+	logger := slog.New(h).With("a", 1)
+
+	logger.Info("first")
+	h.SetOutput(&buf2)
+	logger.Info("second")
+
+	if got, want := buf1.String(), "INFO first a=1\n"; got != want {
+		t.Errorf("unexpected first output: got: %q, want: %q", got, want)
+	}
+	if got, want := buf2.String(), "INFO second a=1\n"; got != want {
+		t.Errorf("unexpected second output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestCLIHandler_settings_concurrent(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+
+	h := NewCLIHandler(&buf1, nil)
+	logger := slog.New(h)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("message", "j", j)
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		h.SetLevel(slog.Level(i % 2))
+		h.SetColor(ColorMode(i % 3))
+		h.SetOutput([]*bytes.Buffer{&buf1, &buf2}[i%2])
+	}
+	wg.Wait()
+}
+
+func TestCLIHandler_OnError(t *testing.T) {
+	var buf bytes.Buffer
+
+	var errs []error
+	var h *CLIHandler
+	h = NewCLIHandler(errWriter{errors.New("broken pipe")}, &HandlerOptions{
+		OmitTime: true,
+		Format:   FormatGitHub,
+		OnError: func(err error) {
+			errs = append(errs, err)
+			// Reconfiguring the handler from OnError must not
+			// deadlock.
+			h.SetOutput(&buf)
+		},
+	})
+	logger := slog.New(h.WithGroup("g"))
+
+	if err := h.StartGroup("group"); err == nil {
+		t.Error("StartGroup did not return error")
+	}
+	logger.Info("message")
+	if err := h.EndGroup(); err != nil {
+		t.Errorf("unexpected EndGroup error: %v", err)
+	}
+
+	if got := h.Errors(); got != 1 {
+		t.Errorf("unexpected number of errors: got: %v, want: 1", got)
+	}
+	if len(errs) != 1 || errs[0].Error() != "broken pipe" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if got, want := buf.String(), "INFO message\n::endgroup::\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+}
+
+func TestCLIHandler_Errors(t *testing.T) {
+	h := NewCLIHandler(errWriter{errors.New("no space left on device")}, nil)
+	logger := slog.New(h).With("a", 1)
+
+	logger.Info("first")
+	logger.Info("second")
+
+	if got := h.Errors(); got != 2 {
+		t.Errorf("unexpected number of errors: got: %v, want: 2", got)
+	}
+}
+
+func TestCLIHandler_Fallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		notice bool
+		want   string
+	}{
+		{
+			name: "silent",
+			want: "INFO first a=1\nINFO second a=1\n",
+		},
+		{
+			name:   "notice",
+			notice: true,
+			want:   "WARN log output failed, switching to fallback a=1 err=\"broken pipe\"\nINFO first a=1\nINFO second a=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fallback bytes.Buffer
+
+			h := NewCLIHandler(errWriter{errors.New("broken pipe")}, &HandlerOptions{
+				OmitTime:       true,
+				Fallback:       &fallback,
+				FallbackNotice: tt.notice,
+			})
+			logger := slog.New(h).With("a", 1)
+
+			logger.Info("first")
+			logger.Info("second")
+
+			if got := fallback.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot:  %q\nwant: %q", got, tt.want)
+			}
+			if got := h.Errors(); got != 1 {
+				t.Errorf("unexpected number of errors: got: %v, want: 1", got)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Fallback_failing(t *testing.T) {
+	h := NewCLIHandler(errWriter{errors.New("primary")}, &HandlerOptions{
+		Fallback:       errWriter{errors.New("fallback")},
+		FallbackNotice: true,
+	})
+	logger := slog.New(h)
+
+	logger.Info("first")
+	logger.Info("second")
+
+	// The primary error, the error writing the notice, the error
+	// writing the first record to the fallback and the error
+	// writing the second record.
+	if got := h.Errors(); got != 4 {
+		t.Errorf("unexpected number of errors: got: %v, want: 4", got)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package clilog
+
+// HandleSignals installs handlers for the SIGUSR1 and SIGUSR2 signals
+// that change the level of h at runtime. On this system, these
+// signals do not exist and HandleSignals does nothing. The returned
+// function does nothing either.
+func HandleSignals(h *CLIHandler) (stop func()) {
+	return func() {}
+}
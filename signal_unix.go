@@ -0,0 +1,55 @@
+//go:build unix
+
+package clilog
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleSignals installs handlers for the SIGUSR1 and SIGUSR2 signals
+// that change the level of h at runtime, so long-running programs can
+// switch to debug logging without restarting. SIGUSR1 lowers the
+// level by one step (e.g. from INFO to DEBUG) and SIGUSR2 raises it
+// (e.g. from DEBUG to INFO). The level stays between LevelTrace and
+// slog.LevelError. The returned function uninstalls the handlers.
+//
+// HandleSignals does nothing on Windows and other systems without
+// these signals.
+func HandleSignals(h *CLIHandler) (stop func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-c:
+				if sig == syscall.SIGUSR1 {
+					h.stepLevel(-4)
+				} else {
+					h.stepLevel(4)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+// stepLevel adds delta to the level of h, keeping it between
+// LevelTrace and slog.LevelError.
+func (h *CLIHandler) stepLevel(delta slog.Level) {
+	h.SetLevel(min(max(h.Level()+delta, LevelTrace), slog.LevelError))
+}
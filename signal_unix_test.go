@@ -0,0 +1,49 @@
+//go:build unix
+
+package clilog
+
+import (
+	"io"
+	"log/slog"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals(t *testing.T) {
+	h := NewCLIHandler(io.Discard, nil)
+	stop := HandleSignals(h)
+	defer stop()
+
+	signals := []struct {
+		sig  syscall.Signal
+		want slog.Level
+	}{
+		{syscall.SIGUSR1, slog.LevelDebug},
+		{syscall.SIGUSR1, LevelTrace},
+		{syscall.SIGUSR1, LevelTrace},
+		{syscall.SIGUSR2, slog.LevelDebug},
+		{syscall.SIGUSR2, slog.LevelInfo},
+		{syscall.SIGUSR2, slog.LevelWarn},
+		{syscall.SIGUSR2, slog.LevelError},
+		{syscall.SIGUSR2, slog.LevelError},
+	}
+	for _, s := range signals {
+		// Every call to SetLevel stores a new pointer, so the
+		// handling of the signal can be detected.
+		prev := h.state.level.Load()
+		if err := syscall.Kill(syscall.Getpid(), s.sig); err != nil {
+			t.Fatalf("could not send signal: %v", err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for h.state.level.Load() == prev {
+			if time.Now().After(deadline) {
+				t.Fatalf("signal %v not handled", s.sig)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if got := h.Level(); got != s.want {
+			t.Errorf("unexpected level after %v: got: %v, want: %v", s.sig, got, s.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// sinkOptions returns a copy of opts suitable for rendering the
+// records forwarded to a system log, which stores the time and the
+// level of the records. Each record is rendered as a single line
+// without colors. If opts is nil, the default options are used.
+func sinkOptions(opts *HandlerOptions) *HandlerOptions {
+	var o HandlerOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.Color = ColorNever
+	o.OmitTime = true
+	o.Format = FormatText
+	o.Multiline = false
+	o.Expanded = false
+	o.ErrorChain = false
+	o.Overflow = OverflowNone
+	o.LineEnding = LineEndingLF
+	o.SplitLevel = nil
+	o.WriterFor = nil
+	o.Fallback = nil
+	o.Template = "{source} {msg}{attrs}"
+	return &o
+}
+
+// newSinkHandler returns a new [CLIHandler] that writes to sink the
+// records rendered with the options returned by sinkOptions. Stack
+// traces are rendered inline, so every record is a single line.
+func newSinkHandler(sink *levelSink, opts *HandlerOptions) *CLIHandler {
+	h := NewCLIHandler(sink, opts)
+	h.inline = true
+	return h
+}
+
+// levelSink is the writer of the CLIHandler wrapped by the handlers
+// that forward records to a system log. It passes the rendered
+// records to a function along with the level of the record being
+// handled, so they can be logged with the matching priority.
+type levelSink struct {
+	write func(level slog.Level, msg string) error
+
+	mu    sync.Mutex
+	level slog.Level // level of the record being handled
+}
+
+// handle passes r to h, which must write to s.
+func (s *levelSink) handle(ctx context.Context, h *CLIHandler, r slog.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.level = r.Level
+	return h.Handle(ctx, r)
+}
+
+// Write passes the rendered record p, without the trailing newline,
+// to the write function. It must be called with mu held.
+func (s *levelSink) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	if err := s.write(s.level, msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
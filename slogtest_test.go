@@ -0,0 +1,83 @@
+package clilog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestSlogtest(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := NewCLIHandler(&buf, nil)
+	results := func() []map[string]any {
+		var ms []map[string]any
+		s := bufio.NewScanner(&buf)
+		for s.Scan() {
+			m, err := parseLine(s.Text())
+			if err != nil {
+				t.Fatalf("could not parse line %q: %v", s.Text(), err)
+			}
+			ms = append(ms, m)
+		}
+		if err := s.Err(); err != nil {
+			t.Fatalf("could not read output: %v", err)
+		}
+		return ms
+	}
+
+	if err := slogtest.TestHandler(h, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// parseLine parses a log line generated by a CLIHandler with default
+// options. Messages must not contain spaces.
+func parseLine(line string) (map[string]any, error) {
+	m := make(map[string]any)
+
+	tok, rest, _ := strings.Cut(line, " ")
+	if _, err := time.Parse(time.RFC3339, tok); err == nil {
+		m["time"] = tok
+		tok, rest, _ = strings.Cut(rest, " ")
+	}
+	m["level"] = tok
+	m["msg"], rest, _ = strings.Cut(rest, " ")
+
+	for rest != "" {
+		key, val, ok := strings.Cut(rest, "=")
+		if !ok {
+			return nil, fmt.Errorf("missing value: %q", rest)
+		}
+		if strings.HasPrefix(val, `"`) {
+			q, err := strconv.QuotedPrefix(val)
+			if err != nil {
+				return nil, fmt.Errorf("malformed quoted value: %w", err)
+			}
+			rest = strings.TrimPrefix(val[len(q):], " ")
+			if val, err = strconv.Unquote(q); err != nil {
+				return nil, fmt.Errorf("malformed quoted value: %w", err)
+			}
+		} else {
+			val, rest, _ = strings.Cut(val, " ")
+		}
+
+		groups := strings.Split(key, ".")
+		gm := m
+		for _, g := range groups[:len(groups)-1] {
+			sub, ok := gm[g].(map[string]any)
+			if !ok {
+				sub = make(map[string]any)
+				gm[g] = sub
+			}
+			gm = sub
+		}
+		gm[groups[len(groups)-1]] = val
+	}
+	return m, nil
+}
@@ -0,0 +1,100 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SourceFormat controls how a [CLIHandler] renders source code
+// positions.
+type SourceFormat int
+
+// Source formats.
+const (
+	// SourceLong renders the full path of the file (e.g.
+	// "/home/user/src/tool/cmd/tool/main.go:42").
+	SourceLong SourceFormat = iota
+
+	// SourceShort renders the base name of the file (e.g.
+	// "main.go:42").
+	SourceShort
+
+	// SourcePackage renders the path of the file relative to its
+	// parent directory, which usually matches the package (e.g.
+	// "tool/main.go:42").
+	SourcePackage
+)
+
+// String returns a name for the source format.
+func (f SourceFormat) String() string {
+	switch f {
+	case SourceLong:
+		return "long"
+	case SourceShort:
+		return "short"
+	case SourcePackage:
+		return "package"
+	default:
+		return fmt.Sprintf("SourceFormat(%d)", int(f))
+	}
+}
+
+// formatSource returns the string representation of the source value
+// v according to the configured source format. The source is rendered
+// as a hyperlink if SourceLinks is set and tty is true.
+func (h *CLIHandler) formatSource(v slog.Value, tty bool) string {
+	src, ok := v.Any().(*slog.Source)
+	if v.Kind() != slog.KindAny || !ok {
+		return v.String()
+	}
+
+	file := src.File
+	switch h.opts.SourceFormat {
+	case SourceShort:
+		file = filepath.Base(file)
+	case SourcePackage:
+		dir, base := filepath.Split(file)
+		file = filepath.Join(filepath.Base(dir), base)
+	}
+
+	s := file + ":" + strconv.Itoa(src.Line)
+	if h.opts.SourceFunc && src.Function != "" {
+		s += " (" + shortFunc(src.Function) + ")"
+	}
+	if h.opts.SourceLinks && tty {
+		s = hyperlink(fileURL(src.File, src.Line), s)
+	}
+	return s
+}
+
+// fileURL returns a file URL pointing to the provided line of file.
+func fileURL(file string, line int) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	path := filepath.ToSlash(file)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths (e.g. "C:/foo").
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Path: path, Fragment: strconv.Itoa(line)}
+	return u.String()
+}
+
+// hyperlink returns text as an OSC 8 terminal hyperlink to url.
+func hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// shortFunc removes the import path from the fully qualified function
+// name fn (e.g. "github.com/user/tool.run" becomes "tool.run").
+func shortFunc(fn string) string {
+	if i := strings.LastIndex(fn, "/"); i >= 0 {
+		fn = fn[i+1:]
+	}
+	return fn
+}
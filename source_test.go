@@ -0,0 +1,77 @@
+package clilog
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestCLIHandler_formatSource(t *testing.T) {
+	src := &slog.Source{
+		Function: "github.com/user/tool/cmd/tool.run.func1",
+		File:     "/home/user/src/tool/cmd/tool/main.go",
+		Line:     42,
+	}
+
+	tests := []struct {
+		name string
+		opts *HandlerOptions
+		tty  bool
+		v    slog.Value
+		want string
+	}{
+		{
+			name: "long",
+			opts: &HandlerOptions{SourceFormat: SourceLong},
+			v:    slog.AnyValue(src),
+			want: "/home/user/src/tool/cmd/tool/main.go:42",
+		},
+		{
+			name: "short",
+			opts: &HandlerOptions{SourceFormat: SourceShort},
+			v:    slog.AnyValue(src),
+			want: "main.go:42",
+		},
+		{
+			name: "package",
+			opts: &HandlerOptions{SourceFormat: SourcePackage},
+			v:    slog.AnyValue(src),
+			want: "tool/main.go:42",
+		},
+		{
+			name: "short,func",
+			opts: &HandlerOptions{SourceFormat: SourceShort, SourceFunc: true},
+			v:    slog.AnyValue(src),
+			want: "main.go:42 (tool.run.func1)",
+		},
+		{
+			name: "links",
+			opts: &HandlerOptions{SourceFormat: SourceShort, SourceLinks: true},
+			tty:  true,
+			v:    slog.AnyValue(src),
+			want: "\x1b]8;;file:///home/user/src/tool/cmd/tool/main.go#42\x1b\\main.go:42\x1b]8;;\x1b\\",
+		},
+		{
+			name: "links not a terminal",
+			opts: &HandlerOptions{SourceFormat: SourceShort, SourceLinks: true},
+			tty:  false,
+			v:    slog.AnyValue(src),
+			want: "main.go:42",
+		},
+		{
+			name: "not a source",
+			opts: &HandlerOptions{SourceFormat: SourceShort},
+			v:    slog.StringValue("/foo/bar.go:1"),
+			want: "/foo/bar.go:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCLIHandler(io.Discard, tt.opts)
+			if got := h.formatSource(tt.v, tt.tty); got != tt.want {
+				t.Errorf("unexpected source: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
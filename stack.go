@@ -0,0 +1,75 @@
+package clilog
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// StackKey is the key of the attributes returned by Stack and
+// StackSkip.
+const StackKey = "stack"
+
+// stackTrace is the value of the attributes returned by Stack and
+// StackSkip. A [CLIHandler] always renders it as a multi-line block.
+type stackTrace string
+
+// String returns the stack trace.
+func (s stackTrace) String() string {
+	return string(s)
+}
+
+// Stack returns an attribute with the stack trace of the calling
+// goroutine, starting at the caller of Stack.
+func Stack() slog.Attr {
+	return StackSkip(1)
+}
+
+// StackSkip returns an attribute with the stack trace of the calling
+// goroutine. The argument skip is the number of stack frames to skip
+// before recording, with 0 identifying the caller of StackSkip.
+func StackSkip(skip int) slog.Attr {
+	return stackAttr(callers(skip + 1))
+}
+
+// callers returns the program counters of the stack frames of the
+// calling goroutine, with 0 identifying the caller of callers.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 32)
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+}
+
+// skipRuntime returns pcs without its leading frames of the runtime
+// package. At least one frame is always kept.
+func skipRuntime(pcs []uintptr) []uintptr {
+	for len(pcs) > 1 {
+		f := runtime.FuncForPC(pcs[0] - 1)
+		if f == nil || !strings.HasPrefix(f.Name(), "runtime.") {
+			break
+		}
+		pcs = pcs[1:]
+	}
+	return pcs
+}
+
+// stackAttr returns an attribute with the stack trace of the provided
+// program counters.
+func stackAttr(pcs []uintptr) slog.Attr {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%v\n\t%v:%v\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return slog.Any(StackKey, stackTrace(b.String()))
+}
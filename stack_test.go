@@ -0,0 +1,54 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	a := Stack()
+	if a.Key != StackKey {
+		t.Errorf("unexpected key: %v", a.Key)
+	}
+	st := a.Value.String()
+	if !strings.HasPrefix(st, "github.com/jroimartin/clilog.TestStack\n\t") {
+		t.Errorf("unexpected stack trace:\n%v", st)
+	}
+	if !strings.Contains(st, "stack_test.go:") {
+		t.Errorf("missing file in stack trace:\n%v", st)
+	}
+}
+
+func TestStackSkip(t *testing.T) {
+	st := stackHelper().Value.String()
+	if !strings.HasPrefix(st, "github.com/jroimartin/clilog.TestStackSkip\n\t") {
+		t.Errorf("unexpected stack trace:\n%v", st)
+	}
+}
+
+func stackHelper() slog.Attr {
+	return StackSkip(1)
+}
+
+func TestCLIHandler_Stack(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true}))
+	logger.Error("message", "a", 1, Stack())
+
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "ERROR message a=1" {
+		t.Errorf("unexpected log line: %q", lines[0])
+	}
+	if lines[1] != "  stack:" {
+		t.Errorf("unexpected block header: %q", lines[1])
+	}
+	if lines[2] != "    github.com/jroimartin/clilog.TestCLIHandler_Stack" {
+		t.Errorf("unexpected block line: %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "        ") || !strings.Contains(lines[3], "stack_test.go:") {
+		t.Errorf("unexpected block line: %q", lines[3])
+	}
+}
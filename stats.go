@@ -0,0 +1,71 @@
+package clilog
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Stats holds the number of records handled by a [CLIHandler] per
+// level. Records with levels between named levels are counted in the
+// closest lower named level (e.g. WARN+2 is counted as WARN).
+type Stats struct {
+	Trace  uint64
+	Debug  uint64
+	Info   uint64
+	Notice uint64
+	Warn   uint64
+	Error  uint64
+	Fatal  uint64
+}
+
+// Stats returns the number of records per level handled by the
+// handler and the handlers derived from it. Records discarded because
+// of their level are not counted.
+func (h *CLIHandler) Stats() Stats {
+	c := &h.state.counts
+	return Stats{
+		Trace:  c[levelIndex(LevelTrace)].Load(),
+		Debug:  c[levelIndex(slog.LevelDebug)].Load(),
+		Info:   c[levelIndex(slog.LevelInfo)].Load(),
+		Notice: c[levelIndex(LevelNotice)].Load(),
+		Warn:   c[levelIndex(slog.LevelWarn)].Load(),
+		Error:  c[levelIndex(slog.LevelError)].Load(),
+		Fatal:  c[levelIndex(LevelFatal)].Load(),
+	}
+}
+
+// Summary returns a summary of the warnings and errors, such as "3
+// warnings, 1 error", intended to be printed at the end of a run.
+// FATAL records are counted as errors. It returns an empty string if
+// there are no warnings nor errors.
+func (s Stats) Summary() string {
+	var parts []string
+	if s.Warn > 0 {
+		parts = append(parts, plural(s.Warn, "warning"))
+	}
+	if n := s.Error + s.Fatal; n > 0 {
+		parts = append(parts, plural(n, "error"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural returns n followed by noun, in plural if n is not 1.
+func plural(n uint64, noun string) string {
+	s := strconv.FormatUint(n, 10) + " " + noun
+	if n != 1 {
+		s += "s"
+	}
+	return s
+}
+
+// levelIndex returns the index of the counter of level in the
+// handler state. Counters are sorted like baseLevels.
+func levelIndex(level slog.Level) int {
+	for i, b := range baseLevels {
+		if level >= b.level {
+			return i
+		}
+	}
+	return len(baseLevels) - 1
+}
@@ -0,0 +1,47 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestCLIHandler_Stats(t *testing.T) {
+	h := NewCLIHandler(&bytes.Buffer{}, &HandlerOptions{Level: slog.LevelDebug})
+	logger := slog.New(h)
+
+	ctx := context.Background()
+	logger.Log(ctx, LevelTrace, "discarded")
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.With("a", 1).Info("info")
+	logger.Log(ctx, LevelNotice, "notice")
+	logger.WithGroup("g").Warn("warn")
+	logger.Log(ctx, slog.LevelWarn+2, "warn+2")
+	logger.Error("error")
+	logger.Log(ctx, LevelFatal+4, "fatal+4")
+
+	want := Stats{Debug: 1, Info: 2, Notice: 1, Warn: 2, Error: 1, Fatal: 1}
+	if got := h.Stats(); got != want {
+		t.Errorf("unexpected stats: got: %+v, want: %+v", got, want)
+	}
+}
+
+func TestStats_Summary(t *testing.T) {
+	tests := []struct {
+		stats Stats
+		want  string
+	}{
+		{Stats{Info: 10}, ""},
+		{Stats{Warn: 1}, "1 warning"},
+		{Stats{Warn: 3, Error: 1}, "3 warnings, 1 error"},
+		{Stats{Error: 1, Fatal: 1}, "2 errors"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.stats.Summary(); got != tt.want {
+			t.Errorf("unexpected summary for %+v: got: %q, want: %q", tt.stats, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// NewStdlogWriter returns an [io.Writer] that passes the messages of
+// a standard [log.Logger] to h as records with the provided level.
+// It allows to render the logs of the dependencies that use the log
+// package with the same handler as the rest of the program. The date
+// and time prefixes added by the log flags (log.Ldate, log.Ltime and
+// log.Lmicroseconds) are removed from the messages, because the
+// records have their own time. Every write is a message, as done by
+// log.Logger.
+//
+// See also [RedirectStdlog].
+func NewStdlogWriter(h slog.Handler, level slog.Level) io.Writer {
+	return &stdlogWriter{h: h, level: level}
+}
+
+// RedirectStdlog makes the standard logger of the log package write
+// its messages to h as records with the provided level, using a
+// writer returned by [NewStdlogWriter]. The flags of the standard
+// logger are cleared, so messages do not include date prefixes. It
+// returns a function that restores the previous output and flags of
+// the standard logger.
+//
+// Unlike [slog.SetDefault], RedirectStdlog does not change the default
+// slog logger.
+func RedirectStdlog(h slog.Handler, level slog.Level) (restore func()) {
+	w, flags := log.Writer(), log.Flags()
+	log.SetOutput(NewStdlogWriter(h, level))
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(w)
+		log.SetFlags(flags)
+	}
+}
+
+// stdlogWriter is the [io.Writer] returned by NewStdlogWriter.
+type stdlogWriter struct {
+	h     slog.Handler
+	level slog.Level
+}
+
+// Write logs p as a message.
+func (w *stdlogWriter) Write(p []byte) (int, error) {
+	ctx := context.Background()
+	if !w.h.Enabled(ctx, w.level) {
+		return len(p), nil
+	}
+	msg := bytes.TrimSuffix(stripStdlogPrefix(p), []byte("\n"))
+	r := slog.NewRecord(time.Now(), w.level, string(msg), 0)
+	if err := w.h.Handle(ctx, r); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stripStdlogPrefix returns s without the leading date ("2009/01/23 ")
+// and time ("01:23:23 " or "01:23:23.123123 ") written by the log
+// package.
+func stripStdlogPrefix(s []byte) []byte {
+	if matchDigits(s, "dddd/dd/dd ") {
+		s = s[len("dddd/dd/dd "):]
+	}
+	switch {
+	case matchDigits(s, "dd:dd:dd.dddddd "):
+		s = s[len("dd:dd:dd.dddddd "):]
+	case matchDigits(s, "dd:dd:dd "):
+		s = s[len("dd:dd:dd "):]
+	}
+	return s
+}
+
+// matchDigits reports whether s starts with pattern, where the
+// character 'd' matches any decimal digit and the rest of characters
+// match themselves.
+func matchDigits(s []byte, pattern string) bool {
+	if len(s) < len(pattern) {
+		return false
+	}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == 'd' {
+			if s[i] < '0' || s[i] > '9' {
+				return false
+			}
+		} else if s[i] != pattern[i] {
+			return false
+		}
+	}
+	return true
+}
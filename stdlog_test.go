@@ -0,0 +1,87 @@
+package clilog
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"testing"
+)
+
+func TestStdlogWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags int
+		msg   string
+		want  string
+	}{
+		{
+			name: "no flags",
+			msg:  "hello world",
+			want: "WARN hello world\n",
+		},
+		{
+			name:  "LstdFlags",
+			flags: log.LstdFlags,
+			msg:   "hello world",
+			want:  "WARN hello world\n",
+		},
+		{
+			name:  "Lmicroseconds",
+			flags: log.Ldate | log.Lmicroseconds | log.LUTC,
+			msg:   "hello world",
+			want:  "WARN hello world\n",
+		},
+		{
+			name:  "Ltime",
+			flags: log.Ltime,
+			msg:   "12:00:00 is not a prefix",
+			want:  "WARN 12:00:00 is not a prefix\n",
+		},
+		{
+			name: "multiline",
+			msg:  "first\nsecond",
+			want: "WARN first\\nsecond\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true})
+			l := log.New(NewStdlogWriter(h, slog.LevelWarn), "", tt.flags)
+			l.Print(tt.msg)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStdlogWriter_level(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true})
+	l := log.New(NewStdlogWriter(h, slog.LevelDebug), "", 0)
+	l.Print("hidden")
+
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestRedirectStdlog(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{OmitTime: true})
+
+	w, flags := log.Writer(), log.Flags()
+	restore := RedirectStdlog(h, slog.LevelInfo)
+	log.Printf("n=%d", 1)
+	restore()
+
+	if got, want := buf.String(), "INFO n=1\n"; got != want {
+		t.Errorf("unexpected output: got: %q, want: %q", got, want)
+	}
+	if log.Writer() != w || log.Flags() != flags {
+		t.Error("standard logger not restored")
+	}
+}
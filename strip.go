@@ -0,0 +1,98 @@
+package clilog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPending is the maximum length of an incomplete escape sequence
+// kept between writes.
+const maxPending = 4096
+
+// StripANSI returns an [io.Writer] that writes to w the data written
+// to it without ANSI escape sequences. It allows to tee the colored
+// output of a handler to a file or a buffer. Escape sequences split
+// across several writes are removed as well, unless they are longer
+// than 4096 bytes or span a newline, in which case they are written as
+// literal text without the leading ESC.
+func StripANSI(w io.Writer) io.Writer {
+	return &stripWriter{w: w}
+}
+
+// stripWriter is the [io.Writer] returned by [StripANSI].
+type stripWriter struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	pending []byte // incomplete escape sequence at the end of the last write
+}
+
+// Write writes p to the underlying writer without ANSI escape
+// sequences. It returns len(p) on success, even if fewer bytes are
+// written to the underlying writer.
+func (sw *stripWriter) Write(p []byte) (int, error) {
+	b := newBuffer()
+	defer b.free()
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	s := p
+	if len(sw.pending) > 0 {
+		sw.pending = append(sw.pending, p...)
+		s = sw.pending
+	}
+	rest := appendStripped(b, s)
+	sw.pending = append(sw.pending[:0], rest...)
+
+	if len(*b) == 0 {
+		return len(p), nil
+	}
+	if _, err := sw.w.Write(*b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// appendStripped appends s to b without ANSI escape sequences. It
+// returns the incomplete escape sequence at the end of s, if any,
+// which is not appended. An incomplete escape sequence that contains a
+// newline or exceeds maxPending is not considered a sequence: its ESC
+// is dropped and the rest is appended as literal text.
+func appendStripped(b *buffer, s []byte) []byte {
+	for len(s) > 0 {
+		if s[0] == '\x1b' && !ansiComplete(s) {
+			if len(s) < maxPending && bytes.IndexByte(s, '\n') < 0 {
+				return s
+			}
+			s = s[1:]
+			continue
+		}
+		if n := ansiLen(s); n > 0 {
+			s = s[n:]
+			continue
+		}
+		b.WriteByte(s[0])
+		s = s[1:]
+	}
+	return nil
+}
+
+// ansiComplete reports whether the escape sequence at the beginning of
+// s is terminated.
+func ansiComplete(s []byte) bool {
+	if len(s) < 2 {
+		return false
+	}
+	n := ansiLen(s)
+	switch s[1] {
+	case '[':
+		c := s[n-1]
+		return n > 2 && c >= 0x40 && c <= 0x7e
+	case ']':
+		return s[n-1] == '\a' || (n > 3 && s[n-2] == '\x1b' && s[n-1] == '\\')
+	default:
+		return true
+	}
+}
@@ -0,0 +1,101 @@
+package clilog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{
+			name:   "plain",
+			writes: []string{"INFO message\n"},
+			want:   "INFO message\n",
+		},
+		{
+			name:   "sgr",
+			writes: []string{"\x1b[1;32mINFO\x1b[0m message\n"},
+			want:   "INFO message\n",
+		},
+		{
+			name:   "hyperlink",
+			writes: []string{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\\n"},
+			want:   "link\n",
+		},
+		{
+			name:   "bel terminator",
+			writes: []string{"\x1b]8;;https://example.com\alink\x1b]8;;\a\n"},
+			want:   "link\n",
+		},
+		{
+			name:   "split csi",
+			writes: []string{"a\x1b", "[1;3", "2mb\x1b[0", "mc"},
+			want:   "abc",
+		},
+		{
+			name:   "split osc",
+			writes: []string{"a\x1b]8;;https://exa", "mple.com\x1b", "\\b"},
+			want:   "ab",
+		},
+		{
+			name:   "trailing escape",
+			writes: []string{"a\x1b"},
+			want:   "a",
+		},
+		{
+			name:   "lone escape",
+			writes: []string{"x\x1b\ny\n"},
+			want:   "x\ny\n",
+		},
+		{
+			name:   "unterminated osc",
+			writes: []string{"a\x1b]8;;http://x", " more text\n", "next line\n"},
+			want:   "a]8;;http://x more text\nnext line\n",
+		},
+		{
+			name:   "unterminated osc before sequence",
+			writes: []string{"a\x1b]8;;http://x\n", "\x1b[1mb\x1b[0m\n"},
+			want:   "a]8;;http://x\nb\n",
+		},
+		{
+			name:   "long unterminated osc",
+			writes: []string{"\x1b]", strings.Repeat("x", maxPending)},
+			want:   "]" + strings.Repeat("x", maxPending),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := StripANSI(&buf)
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil {
+					t.Fatalf("write error: %v", err)
+				}
+				if n != len(s) {
+					t.Errorf("unexpected number of bytes: got: %v, want: %v", n, len(s))
+				}
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output: got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripANSI_handler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(StripANSI(&buf), &HandlerOptions{OmitTime: true, Color: ColorAlways}))
+	logger.Error("request failed", "status", 500)
+
+	if want := "ERROR request failed status=500\n"; buf.String() != want {
+		t.Errorf("unexpected output: got: %q, want: %q", buf.String(), want)
+	}
+}
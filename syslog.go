@@ -0,0 +1,98 @@
+//go:build unix
+
+package clilog
+
+import (
+	"context"
+	"log/slog"
+	"log/syslog"
+)
+
+// SyslogWriter is the subset of the methods of [syslog.Writer] used
+// by a [SyslogHandler].
+type SyslogWriter interface {
+	Debug(m string) error
+	Info(m string) error
+	Notice(m string) error
+	Warning(m string) error
+	Err(m string) error
+}
+
+// SyslogHandler is a [slog.Handler] that forwards records to the
+// system log. The level of each record is mapped to a syslog
+// priority and the rest of the record is rendered as a single line
+// by a [CLIHandler] without time and colors, which are provided by
+// syslog. To log both to the console and to syslog, combine it with
+// another handler using [MultiHandler].
+//
+// Handlers derived from a SyslogHandler using WithAttrs or WithGroup
+// share its writer.
+type SyslogHandler struct {
+	h    *CLIHandler
+	sink *levelSink
+}
+
+// NewSyslogHandler returns a new [SyslogHandler] that writes to w,
+// which is usually created with [syslog.New] or [syslog.Dial]. If
+// opts is nil, the default options are used. The options Color,
+// OmitTime, Format, Multiline, Expanded, ErrorChain, Overflow,
+// LineEnding, SplitLevel, WriterFor, Fallback and Template are
+// ignored.
+func NewSyslogHandler(w SyslogWriter, opts *HandlerOptions) *SyslogHandler {
+	sink := &levelSink{write: func(level slog.Level, msg string) error {
+		return writeSyslog(w, level, msg)
+	}}
+	return &SyslogHandler{h: newSinkHandler(sink, sinkOptions(opts)), sink: sink}
+}
+
+// DialSyslog returns a new [SyslogHandler] connected to the local
+// syslog daemon. The records are logged with the facility LOG_USER
+// and the provided tag. If tag is empty, the name of the program is
+// used.
+func DialSyslog(tag string, opts *HandlerOptions) (*SyslogHandler, error) {
+	w, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return NewSyslogHandler(w, opts), nil
+}
+
+// Enabled reports whether the handler handles records at the given
+// level.
+func (h *SyslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle writes the record to syslog with the priority matching its
+// level.
+func (h *SyslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.sink.handle(ctx, h.h, r)
+}
+
+// WithAttrs returns a new SyslogHandler whose attributes consist of
+// both the receiver's attributes and the arguments.
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SyslogHandler{h: h.h.WithAttrs(attrs).(*CLIHandler), sink: h.sink}
+}
+
+// WithGroup returns a new SyslogHandler with the given group appended
+// to the receiver's existing groups.
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	return &SyslogHandler{h: h.h.WithGroup(name).(*CLIHandler), sink: h.sink}
+}
+
+// writeSyslog writes msg to w with the priority matching level.
+func writeSyslog(w SyslogWriter, level slog.Level, msg string) error {
+	switch {
+	case level >= slog.LevelError:
+		return w.Err(msg)
+	case level >= slog.LevelWarn:
+		return w.Warning(msg)
+	case level >= LevelNotice:
+		return w.Notice(msg)
+	case level >= slog.LevelInfo:
+		return w.Info(msg)
+	default:
+		return w.Debug(msg)
+	}
+}
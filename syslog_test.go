@@ -0,0 +1,140 @@
+//go:build unix
+
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSyslog is a [SyslogWriter] that records the messages written
+// to it prefixed by their priority.
+type fakeSyslog struct {
+	msgs []string
+	err  error
+}
+
+func (w *fakeSyslog) write(prio, m string) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.msgs = append(w.msgs, prio+": "+m)
+	return nil
+}
+
+func (w *fakeSyslog) Debug(m string) error   { return w.write("debug", m) }
+func (w *fakeSyslog) Info(m string) error    { return w.write("info", m) }
+func (w *fakeSyslog) Notice(m string) error  { return w.write("notice", m) }
+func (w *fakeSyslog) Warning(m string) error { return w.write("warning", m) }
+func (w *fakeSyslog) Err(m string) error     { return w.write("err", m) }
+
+func TestSyslogHandler(t *testing.T) {
+	w := &fakeSyslog{}
+	logger := slog.New(NewSyslogHandler(w, &HandlerOptions{
+		Level: LevelTrace,
+		Color: ColorAlways,
+	}))
+
+	logger.Log(context.Background(), LevelTrace, "trace")
+	logger.Debug("debug")
+	logger.Info("info", "n", 1)
+	logger.Log(context.Background(), LevelNotice, "notice")
+	logger.Warn("warn")
+	logger.With("job", "backup").WithGroup("g").Error("error", "code", 2)
+
+	want := []string{
+		"debug: trace",
+		"debug: debug",
+		"info: info n=1",
+		"notice: notice",
+		"warning: warn",
+		"err: error job=backup g.code=2",
+	}
+	if len(w.msgs) != len(want) {
+		t.Fatalf("unexpected messages: got: %q, want: %q", w.msgs, want)
+	}
+	for i := range want {
+		if w.msgs[i] != want[i] {
+			t.Errorf("unexpected message %d: got: %q, want: %q", i, w.msgs[i], want[i])
+		}
+	}
+}
+
+func TestSyslogHandler_level(t *testing.T) {
+	w := &fakeSyslog{}
+	logger := slog.New(NewSyslogHandler(w, &HandlerOptions{Level: slog.LevelWarn}))
+
+	logger.Info("info")
+	logger.Warn("warn")
+
+	if len(w.msgs) != 1 || w.msgs[0] != "warning: warn" {
+		t.Errorf("unexpected messages: %q", w.msgs)
+	}
+}
+
+func TestSyslogHandler_error(t *testing.T) {
+	errSyslog := errors.New("syslog error")
+	w := &fakeSyslog{err: errSyslog}
+	h := NewSyslogHandler(w, nil)
+
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "message", 0)); !errors.Is(err, errSyslog) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSyslogHandler_singleLine(t *testing.T) {
+	w := &fakeSyslog{}
+	logger := slog.New(NewSyslogHandler(w, &HandlerOptions{ErrorChain: true, Expanded: true, Multiline: true}))
+
+	logger.Error("failure", "err", fmt.Errorf("wrap: %w", errors.New("inner")), "out", "line 1\nline 2")
+	logger.Error("panic", Stack())
+
+	if len(w.msgs) != 2 {
+		t.Fatalf("unexpected messages: %q", w.msgs)
+	}
+	for _, msg := range w.msgs {
+		if strings.Contains(msg, "\n") {
+			t.Errorf("message is not a single line: %q", msg)
+		}
+	}
+	if want := `err: failure err="wrap: inner" out="line 1\nline 2"`; w.msgs[0] != want {
+		t.Errorf("unexpected message: got: %q, want: %q", w.msgs[0], want)
+	}
+}
+
+func TestSyslogHandler_LineEnding(t *testing.T) {
+	w := &fakeSyslog{}
+	logger := slog.New(NewSyslogHandler(w, &HandlerOptions{LineEnding: LineEndingCRLF}))
+
+	logger.Info("info", "n", 1)
+
+	if len(w.msgs) != 1 || w.msgs[0] != "info: info n=1" {
+		t.Errorf("unexpected messages: %q", w.msgs)
+	}
+}
+
+func TestSyslogHandler_WriterFor(t *testing.T) {
+	var console bytes.Buffer
+
+	w := &fakeSyslog{}
+	logger := slog.New(NewSyslogHandler(w, &HandlerOptions{
+		WriterFor: func(slog.Level) io.Writer { return &console },
+		Fallback:  &console,
+	}))
+
+	logger.Info("info")
+
+	if len(w.msgs) != 1 || w.msgs[0] != "info: info" {
+		t.Errorf("unexpected messages: %q", w.msgs)
+	}
+	if console.Len() != 0 {
+		t.Errorf("unexpected console output: %q", console.String())
+	}
+}
@@ -0,0 +1,95 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// Task is a unit of work of a program, such as a build step, whose
+// start and outcome are logged. It is created by [Begin].
+type Task struct {
+	logger *slog.Logger
+	title  string
+	start  time.Time
+	done   atomic.Bool
+}
+
+// Begin logs the start of the task with the provided title at
+// LevelInfo (e.g. "Compiling assets...") and returns the [Task]. The
+// arguments are converted to attributes as in [slog.Logger.Log] and
+// are added to the lines logged by the task. The outcome of the task
+// is logged by calling [Task.Done].
+func Begin(logger *slog.Logger, title string, args ...any) *Task {
+	t := &Task{
+		logger: logger.With(args...),
+		title:  title,
+		start:  time.Now(),
+	}
+	t.log(slog.LevelInfo, title+"...", nil)
+	return t
+}
+
+// Done logs the outcome of the task. If err is nil, the task
+// succeeded and its title and duration are logged at LevelNotice
+// (e.g. "Compiling assets (1.2s)"). Otherwise, they are logged at
+// LevelError along with err. With [IconsReplace], the levels are
+// rendered as "✔" and "✖" respectively. Done returns err, so it can
+// be used in return statements. Calls to Done after the first one
+// do not log anything.
+func (t *Task) Done(err error) error {
+	if !t.done.CompareAndSwap(false, true) {
+		return err
+	}
+	msg := t.title + " (" + taskDuration(time.Since(t.start)) + ")"
+	if err != nil {
+		t.log(slog.LevelError, msg, []slog.Attr{slog.Any("err", err)})
+	} else {
+		t.log(LevelNotice, msg, nil)
+	}
+	return err
+}
+
+// log logs a line of the task. The source code position of the
+// record is the caller of the exported method calling log.
+func (t *Task) log(level slog.Level, msg string, attrs []slog.Attr) {
+	logCaller(t.logger, 1, level, msg, attrs)
+}
+
+// logCaller logs a record with logger. The source code position of
+// the record is the caller of the exported function calling
+// logCaller, skipping the provided number of intermediate frames.
+func logCaller(logger *slog.Logger, skip int, level slog.Level, msg string, attrs []slog.Attr) {
+	logPC(logger, callerPC(skip+1), level, msg, attrs)
+}
+
+// logPC logs a record with logger whose source code position is pc.
+func logPC(logger *slog.Logger, pc uintptr, level slog.Level, msg string, attrs []slog.Attr) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+	r.AddAttrs(attrs...)
+	_ = logger.Handler().Handle(ctx, r)
+}
+
+// callerPC returns the program counter of the caller of the function
+// calling callerPC, skipping the provided number of additional
+// frames.
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(skip+3, pcs[:])
+	return pcs[0]
+}
+
+// taskDuration formats the duration of a task, rounded to tenths of
+// a second, or to milliseconds if it is shorter than a second.
+func taskDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,61 @@
+package clilog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTask(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, AddSource: true, SourceFormat: SourceShort}))
+
+	task := Begin(logger, "Compiling assets", "dir", "web")
+	if err := task.Done(nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	task.Done(errors.New("ignored"))
+
+	re := regexp.MustCompile(`^INFO task_test.go:\d+ Compiling assets\.\.\. dir=web\n` +
+		`NOTICE task_test.go:\d+ Compiling assets \([0-9.]+m?s\) dir=web\n$`)
+	if got := buf.String(); !re.MatchString(got) {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
+func TestTask_error(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &HandlerOptions{OmitTime: true, Icons: IconsReplace}))
+
+	errBuild := errors.New("build failed")
+	task := Begin(logger, "Compiling assets")
+	if err := task.Done(errBuild); err != errBuild {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	re := regexp.MustCompile(`^• Compiling assets\.\.\.\n✖ Compiling assets \([0-9.]+m?s\) err="build failed"\n$`)
+	if got := buf.String(); !re.MatchString(got) {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
+func TestTaskDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{1234 * time.Microsecond, "1ms"},
+		{999 * time.Millisecond, "999ms"},
+		{1234 * time.Millisecond, "1.2s"},
+		{61*time.Second + 250*time.Millisecond, "1m1.3s"},
+	}
+
+	for _, tt := range tests {
+		if got := taskDuration(tt.d); got != tt.want {
+			t.Errorf("unexpected duration for %v: got: %v, want: %v", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package clilog
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// templateField identifies the component rendered by a part of a
+// template.
+type templateField int
+
+// Template fields.
+const (
+	fieldLiteral templateField = iota
+	fieldTime
+	fieldLevel
+	fieldSource
+	fieldMessage
+	fieldAttrs
+)
+
+// templateFields maps placeholder names to template fields.
+var templateFields = map[string]templateField{
+	slog.TimeKey:    fieldTime,
+	slog.LevelKey:   fieldLevel,
+	slog.SourceKey:  fieldSource,
+	slog.MessageKey: fieldMessage,
+	"attrs":         fieldAttrs,
+}
+
+// templatePart is a part of a compiled template.
+type templatePart struct {
+	field templateField
+	text  string // literal text, if field is fieldLiteral
+}
+
+// parseTemplate compiles the layout template s. Placeholders not
+// naming a known field are kept as literal text.
+func parseTemplate(s string) []templatePart {
+	var (
+		parts []templatePart
+		lit   strings.Builder
+	)
+	for s != "" {
+		i := strings.IndexByte(s, '{')
+		if i < 0 {
+			lit.WriteString(s)
+			break
+		}
+		lit.WriteString(s[:i])
+		s = s[i:]
+
+		j := strings.IndexByte(s, '}')
+		if j < 0 {
+			lit.WriteString(s)
+			break
+		}
+		field, ok := templateFields[s[1:j]]
+		if !ok {
+			lit.WriteString(s[:j+1])
+			s = s[j+1:]
+			continue
+		}
+		if lit.Len() > 0 {
+			parts = append(parts, templatePart{text: lit.String()})
+			lit.Reset()
+		}
+		parts = append(parts, templatePart{field: field})
+		s = s[j+1:]
+	}
+	if lit.Len() > 0 {
+		parts = append(parts, templatePart{text: lit.String()})
+	}
+	return parts
+}
+
+// appendTemplate appends to buf the record r rendered according to
+// the configured template. Whitespace following a component that
+// renders nothing (e.g. the time when OmitTime is set) is omitted.
+func (h *CLIHandler) appendTemplate(buf *attrBuffer, out *output, r slog.Record) {
+	b := buf.line
+	empty := false
+	for _, p := range h.tmpl {
+		if p.field != fieldAttrs {
+			buf.flushPad()
+		}
+		switch p.field {
+		case fieldLiteral:
+			if !empty || strings.TrimSpace(p.text) != "" {
+				b.WriteString(p.text)
+			}
+			empty = false
+		case fieldTime:
+			empty = !h.appendTime(b, out, r)
+		case fieldLevel:
+			empty = !h.appendLevel(b, out, r)
+		case fieldSource:
+			empty = !h.appendSource(b, out, r)
+		case fieldMessage:
+			buf.msg = len(*b)
+			empty = !h.appendMessage(b, r)
+			h.padMessage(buf)
+		case fieldAttrs:
+			empty = !h.appendAttrs(buf, r)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package clilog
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []templatePart
+	}{
+		{
+			name: "fields",
+			s:    "{level} {time} {source} {msg}{attrs}",
+			want: []templatePart{
+				{field: fieldLevel},
+				{text: " "},
+				{field: fieldTime},
+				{text: " "},
+				{field: fieldSource},
+				{text: " "},
+				{field: fieldMessage},
+				{field: fieldAttrs},
+			},
+		},
+		{
+			name: "literals",
+			s:    "[{time}] {msg}:",
+			want: []templatePart{
+				{text: "["},
+				{field: fieldTime},
+				{text: "] "},
+				{field: fieldMessage},
+				{text: ":"},
+			},
+		},
+		{
+			name: "unknown placeholder",
+			s:    "{foo} {msg}",
+			want: []templatePart{
+				{text: "{foo} "},
+				{field: fieldMessage},
+			},
+		},
+		{
+			name: "unterminated placeholder",
+			s:    "{msg} {time",
+			want: []templatePart{
+				{field: fieldMessage},
+				{text: " {time"},
+			},
+		},
+		{
+			name: "empty",
+			s:    "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTemplate(tt.s); !slices.Equal(got, tt.want) {
+				t.Errorf("unexpected parts: got: %+v, want: %+v", got, tt.want)
+			}
+		})
+	}
+}
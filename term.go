@@ -0,0 +1,35 @@
+package clilog
+
+import "io"
+
+// isTerminal reports whether w is a terminal. Only writers with a
+// file descriptor (i.e. those implementing an Fd method) are
+// considered.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return isTerminalFd(f.Fd())
+}
+
+// enableColors prepares w to render colors. It reports false if w is
+// a terminal that cannot interpret ANSI escape sequences. Writers
+// that are not terminals are not modified.
+func enableColors(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok || !isTerminalFd(f.Fd()) {
+		return true
+	}
+	return enableVirtualTerminal(f.Fd())
+}
+
+// terminalWidth returns the width in columns of w. It returns 0 if w
+// is not a terminal or its width cannot be determined.
+func terminalWidth(w io.Writer) int {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return 0
+	}
+	return terminalWidthFd(f.Fd())
+}
@@ -0,0 +1,24 @@
+package clilog
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// isTerminalFd reports whether fd refers to a terminal.
+func isTerminalFd(fd uintptr) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
+
+// terminalWidthFd returns the width in columns of the terminal
+// referred to by fd. It returns 0 if the width cannot be determined.
+func terminalWidthFd(fd uintptr) int {
+	var ws struct{ row, col, xpixel, ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.col)
+}
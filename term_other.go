@@ -0,0 +1,17 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package clilog
+
+// isTerminalFd reports whether fd refers to a terminal. Terminal
+// detection is not supported on this platform, so it always returns
+// false.
+func isTerminalFd(fd uintptr) bool {
+	return false
+}
+
+// terminalWidthFd returns the width in columns of the terminal
+// referred to by fd. Terminal detection is not supported on this
+// platform, so it always returns 0.
+func terminalWidthFd(fd uintptr) int {
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package clilog
+
+// enableVirtualTerminal prepares the terminal referred to by fd to
+// interpret ANSI escape sequences. Terminals on this platform always
+// interpret them, so it always returns true.
+func enableVirtualTerminal(fd uintptr) bool {
+	return true
+}
@@ -0,0 +1,66 @@
+package clilog
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that
+// causes the console to interpret ANSI escape sequences.
+const enableVirtualTerminalProcessing = 0x0004
+
+// Functions of kernel32.dll not provided by the syscall package.
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+// consoleScreenBufferInfo is the CONSOLE_SCREEN_BUFFER_INFO
+// structure.
+type consoleScreenBufferInfo struct {
+	size              struct{ x, y int16 }
+	cursorPosition    struct{ x, y int16 }
+	attributes        uint16
+	window            struct{ left, top, right, bottom int16 }
+	maximumWindowSize struct{ x, y int16 }
+}
+
+// isTerminalFd reports whether fd refers to a console.
+func isTerminalFd(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
+
+// enableVirtualTerminal enables the virtual terminal processing of
+// the console referred to by fd, so it interprets ANSI escape
+// sequences. It returns false if the console does not support it
+// (e.g. versions of Windows older than Windows 10).
+func enableVirtualTerminal(fd uintptr) bool {
+	var mode uint32
+	if err := syscall.GetConsoleMode(syscall.Handle(fd), &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	if err := procSetConsoleMode.Find(); err != nil {
+		return false
+	}
+	r, _, _ := procSetConsoleMode.Call(fd, uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
+
+// terminalWidthFd returns the width in columns of the console
+// referred to by fd. It returns 0 if the width cannot be determined.
+func terminalWidthFd(fd uintptr) int {
+	if err := procGetConsoleScreenBufferInfo.Find(); err != nil {
+		return 0
+	}
+	var info consoleScreenBufferInfo
+	r, _, _ := procGetConsoleScreenBufferInfo.Call(fd, uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return 0
+	}
+	return int(info.window.right-info.window.left) + 1
+}
@@ -0,0 +1,120 @@
+package clilog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// ExitTrackerOptions are options for an [ExitTracker]. A zero
+// ExitTrackerOptions consists entirely of default values.
+type ExitTrackerOptions struct {
+	// Level is the minimum level of the records that cause a
+	// nonzero exit code. If Level is nil, the tracker assumes
+	// LevelError.
+	Level slog.Leveler
+
+	// Code is the exit code returned by ExitCode when a record at
+	// or above Level has been logged. If Code is zero, the
+	// tracker assumes 1.
+	Code int
+}
+
+// ExitTracker is a [slog.Handler] that records the maximum level of
+// the records passed to another handler, so programs can exit with a
+// nonzero code if an error has been reported:
+//
+//	tracker := clilog.NewExitTracker(h, nil)
+//	logger := slog.New(tracker)
+//	...
+//	os.Exit(tracker.ExitCode())
+//
+// The records at or above the tracker level are tracked even if the
+// wrapped handler discards them. Handlers derived from an ExitTracker
+// using WithAttrs or WithGroup share its state.
+type ExitTracker struct {
+	h     slog.Handler
+	opts  ExitTrackerOptions
+	state *trackerState
+}
+
+// trackerState is the state shared by an ExitTracker and the handlers
+// derived from it.
+type trackerState struct {
+	mu    sync.Mutex
+	max   slog.Level
+	count int // number of records tracked
+}
+
+// NewExitTracker returns a new [ExitTracker] that passes records to h.
+// If opts is nil, the default options are used.
+func NewExitTracker(h slog.Handler, opts *ExitTrackerOptions) *ExitTracker {
+	if opts == nil {
+		opts = &ExitTrackerOptions{}
+	}
+	t := &ExitTracker{h: h, opts: *opts, state: &trackerState{}}
+	if t.opts.Level == nil {
+		t.opts.Level = slog.LevelError
+	}
+	if t.opts.Code == 0 {
+		t.opts.Code = 1
+	}
+	return t
+}
+
+// Enabled reports whether the wrapped handler handles records at the
+// given level or the level is at or above the tracker level.
+func (t *ExitTracker) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= t.opts.Level.Level() || t.h.Enabled(ctx, level)
+}
+
+// Handle records the level of r and passes it to the wrapped handler
+// if it is enabled for its level.
+func (t *ExitTracker) Handle(ctx context.Context, r slog.Record) error {
+	t.state.mu.Lock()
+	if t.state.count == 0 || r.Level > t.state.max {
+		t.state.max = r.Level
+	}
+	t.state.count++
+	t.state.mu.Unlock()
+
+	if !t.h.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return t.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new ExitTracker whose wrapped handler is the
+// result of calling WithAttrs on the receiver's wrapped handler.
+func (t *ExitTracker) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ExitTracker{h: t.h.WithAttrs(attrs), opts: t.opts, state: t.state}
+}
+
+// WithGroup returns a new ExitTracker whose wrapped handler is the
+// result of calling WithGroup on the receiver's wrapped handler.
+func (t *ExitTracker) WithGroup(name string) slog.Handler {
+	return &ExitTracker{h: t.h.WithGroup(name), opts: t.opts, state: t.state}
+}
+
+// MaxLevel returns the maximum level of the records handled by the
+// tracker. It returns false if no record has been handled.
+func (t *ExitTracker) MaxLevel() (slog.Level, bool) {
+	t.state.mu.Lock()
+	defer t.state.mu.Unlock()
+
+	return t.state.max, t.state.count > 0
+}
+
+// ExitCode returns the configured exit code if a record at or above
+// the tracker level has been handled, or 0 otherwise.
+func (t *ExitTracker) ExitCode() int {
+	if level, ok := t.MaxLevel(); ok && level >= t.opts.Level.Level() {
+		return t.opts.Code
+	}
+	return 0
+}
+
+// wrapped returns the handler wrapped by t.
+func (t *ExitTracker) wrapped() []slog.Handler {
+	return []slog.Handler{t.h}
+}
@@ -0,0 +1,71 @@
+package clilog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestExitTracker(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   *ExitTrackerOptions
+		levels []slog.Level
+		want   int
+	}{
+		{
+			name: "no records",
+			want: 0,
+		},
+		{
+			name:   "info and warnings",
+			levels: []slog.Level{slog.LevelInfo, slog.LevelWarn, slog.LevelDebug},
+			want:   0,
+		},
+		{
+			name:   "error",
+			levels: []slog.Level{slog.LevelInfo, slog.LevelError, slog.LevelInfo},
+			want:   1,
+		},
+		{
+			name:   "Level",
+			opts:   &ExitTrackerOptions{Level: slog.LevelWarn, Code: 3},
+			levels: []slog.Level{slog.LevelInfo, slog.LevelWarn},
+			want:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewExitTracker(NewCLIHandler(&bytes.Buffer{}, nil), tt.opts)
+			logger := slog.New(tracker).With("a", 1).WithGroup("g")
+			for _, level := range tt.levels {
+				logger.Log(context.Background(), level, "message")
+			}
+			if got := tracker.ExitCode(); got != tt.want {
+				t.Errorf("unexpected exit code: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitTracker_disabledLevel(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLIHandler(&buf, &HandlerOptions{Level: LevelFatal})
+	tracker := NewExitTracker(h, nil)
+	logger := slog.New(tracker)
+
+	logger.Info("ignored")
+	logger.Error("hidden")
+
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+	if level, ok := tracker.MaxLevel(); !ok || level != slog.LevelError {
+		t.Errorf("unexpected max level: %v, %v", level, ok)
+	}
+	if got := tracker.ExitCode(); got != 1 {
+		t.Errorf("unexpected exit code: %v", got)
+	}
+}
@@ -0,0 +1,98 @@
+package clilog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Transcript is an [io.Writer] that mirrors the output written to
+// another writer, usually the console, to a transcript file. ANSI
+// escape sequences are removed from the transcript, so a colored
+// handler writing to a Transcript produces a plain text log of the
+// session. Programs can refer users to the transcript (see Path)
+// when they fail.
+//
+// A Transcript exposes the file descriptor of the console, so
+// handlers writing to it enable colors and detect the width of the
+// terminal as if they wrote to the console directly.
+type Transcript struct {
+	w     io.Writer
+	path  string
+	strip io.Writer
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewTranscript returns a new [Transcript] that writes to w and to the
+// file at path, which is truncated. The parent directories of path are
+// created if needed.
+func NewTranscript(w io.Writer, path string) (*Transcript, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Transcript{w: w, path: path, strip: StripANSI(f), f: f}, nil
+}
+
+// TranscriptPath returns the conventional path of the transcript of
+// the last run of the named program, which is in its directory within
+// the user cache directory (e.g. "~/.cache/tool/last-run.log").
+func TranscriptPath(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name, "last-run.log"), nil
+}
+
+// Path returns the path of the transcript file.
+func (t *Transcript) Path() string {
+	return t.path
+}
+
+// Write writes p to the console and, without ANSI escape sequences,
+// to the transcript file. Errors writing the transcript are only
+// returned if writing to the console succeeds. Once the transcript is
+// closed, p is only written to the console.
+func (t *Transcript) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.f == nil {
+		return n, err
+	}
+	if _, ferr := t.strip.Write(p); err == nil {
+		err = ferr
+	}
+	return n, err
+}
+
+// Fd returns the file descriptor of the console. If the console does
+// not have a file descriptor, it returns an invalid one.
+func (t *Transcript) Fd() uintptr {
+	if f, ok := t.w.(interface{ Fd() uintptr }); ok {
+		return f.Fd()
+	}
+	return ^uintptr(0)
+}
+
+// Close closes the transcript file. The console is not closed.
+func (t *Transcript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.f == nil {
+		return os.ErrClosed
+	}
+	err := t.f.Close()
+	t.f = nil
+	return err
+}
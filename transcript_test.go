@@ -0,0 +1,75 @@
+package clilog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscript(t *testing.T) {
+	var console bytes.Buffer
+
+	path := filepath.Join(t.TempDir(), "tool", "last-run.log")
+	tr, err := NewTranscript(&console, path)
+	if err != nil {
+		t.Fatalf("could not create transcript: %v", err)
+	}
+	if tr.Path() != path {
+		t.Errorf("unexpected path: %v", tr.Path())
+	}
+
+	logger := slog.New(NewCLIHandler(tr, &HandlerOptions{OmitTime: true, Color: ColorAlways}))
+	logger.Warn("disk almost full", "err", errors.New("95% used"))
+	if err := tr.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	if got := console.String(); !strings.Contains(got, "\x1b[") {
+		t.Errorf("console output is not colored: %q", got)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read transcript: %v", err)
+	}
+	if want := "WARN disk almost full err=\"95% used\"\n"; string(got) != want {
+		t.Errorf("unexpected transcript: got: %q, want: %q", got, want)
+	}
+}
+
+func TestTranscript_closed(t *testing.T) {
+	var console bytes.Buffer
+
+	tr, err := NewTranscript(&console, filepath.Join(t.TempDir(), "last-run.log"))
+	if err != nil {
+		t.Fatalf("could not create transcript: %v", err)
+	}
+	tr.Close()
+
+	var errs []error
+	logger := slog.New(NewCLIHandler(tr, &HandlerOptions{
+		OmitTime: true,
+		OnError:  func(err error) { errs = append(errs, err) },
+	}))
+	logger.Info("message")
+
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if got := console.String(); got != "INFO message\n" {
+		t.Errorf("unexpected console output: %q", got)
+	}
+}
+
+func TestTranscriptPath(t *testing.T) {
+	path, err := TranscriptPath("tool")
+	if err != nil {
+		t.Skipf("no cache directory: %v", err)
+	}
+	if want := filepath.Join("tool", "last-run.log"); !strings.HasSuffix(path, want) {
+		t.Errorf("unexpected path: %v", path)
+	}
+}
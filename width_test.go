@@ -0,0 +1,28 @@
+package clilog
+
+import "testing"
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want int
+	}{
+		{'a', 1},
+		{'ñ', 1},
+		{'\u0301', 0},
+		{'\u200d', 0},
+		{'\ufe0f', 0},
+		{'日', 2},
+		{'한', 2},
+		{'Ａ', 2},
+		{'👍', 2},
+		{'\U00020000', 2},
+		{'→', 1},
+	}
+
+	for _, tt := range tests {
+		if got := runeWidth(tt.r); got != tt.want {
+			t.Errorf("unexpected width for %U: got: %v, want: %v", tt.r, got, tt.want)
+		}
+	}
+}